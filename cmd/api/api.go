@@ -7,7 +7,10 @@ import (
 
 	"github.com/ArdiSasongko/SocialNetwork/cmd/api/v1/handlers"
 	"github.com/ArdiSasongko/SocialNetwork/cmd/api/v1/middlewares"
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
 	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -17,6 +20,7 @@ type application struct {
 	config     Config
 	handler    handlers.Handler
 	middleware middlewares.Middleware
+	service    service.Service
 }
 
 type Config struct {
@@ -24,6 +28,10 @@ type Config struct {
 	db         dbConfig
 	auth       authConfig
 	cloudinary cldConfig
+	mail       mailConfig
+	oidc       oidcConfig
+	jobs       jobsConfig
+	rbac       rbacConfig
 }
 
 type dbConfig struct {
@@ -34,9 +42,11 @@ type dbConfig struct {
 }
 
 type authConfig struct {
-	secret string
-	exp    time.Duration
-	iss    string
+	secret    string
+	exp       time.Duration
+	iss       string
+	keys      string
+	activeKID string
 }
 
 type cldConfig struct {
@@ -44,6 +54,37 @@ type cldConfig struct {
 	folder string
 }
 
+type mailConfig struct {
+	driver    string
+	fromEmail string
+	logFile   string
+	smtp      smtpConfig
+}
+
+type oidcConfig struct {
+	name         string
+	issuer       string
+	clientID     string
+	clientSecret string
+	redirectURL  string
+}
+
+type jobsConfig struct {
+	purgeInterval   time.Duration
+	publishInterval time.Duration
+}
+
+type rbacConfig struct {
+	refresh time.Duration
+}
+
+type smtpConfig struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
 func (app *application) mount() http.Handler {
 	r := chi.NewRouter()
 
@@ -55,7 +96,7 @@ func (app *application) mount() http.Handler {
 		// AllowedOrigins:   []string{"https://foo.com"}, // Use this to allow specific origin hosts
 		AllowedOrigins: []string{env.GetString("ALLOWED_ORIGIN", "http://*")},
 		// AllowOriginFunc:  func(r *http.Request, origin string) bool { return true },
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 		ExposedHeaders:   []string{"Link"},
 		AllowCredentials: false,
@@ -63,43 +104,134 @@ func (app *application) mount() http.Handler {
 	}))
 	r.Use(middleware.Timeout(60 * time.Second))
 
+	r.Get("/.well-known/jwks.json", app.handler.JWKS.Get)
+
 	r.Route("/v1", func(r chi.Router) {
 		r.Get("/health", app.handler.Health.Get)
 
 		// auth handler
 		r.Route("/authentication", func(r chi.Router) {
 			r.Post("/register", app.handler.Auth.RegisterUser)
+			r.Put("/activate/{token}", app.handler.Auth.ActivateUser)
 			r.Post("/login", app.handler.Auth.LoginUser)
+			r.Post("/mfa/verify", app.handler.Auth.VerifyMFA)
+			r.Post("/refresh", app.handler.Auth.RefreshToken)
+			r.Post("/password/forgot", app.handler.Auth.ForgotPassword)
+			r.Post("/password/reset", app.handler.Auth.ResetPassword)
+			r.Post("/restore", app.handler.Auth.RestoreAccount)
+			r.Get("/oidc/login", app.handler.Auth.StartOIDC)
+			r.Get("/oidc/callback", app.handler.Auth.OIDCCallback)
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.middleware.AuthMiddleware)
+				r.Use(app.middleware.RequireSession)
+				r.Post("/logout", app.handler.Auth.Logout)
+			})
 		})
 
 		// profile handler
 		r.Route("/profile", func(r chi.Router) {
 			r.Use(app.middleware.AuthMiddleware)
-			r.Get("/", app.handler.Users.GetProfile)
+			r.With(app.middleware.RequireScope(auth.ScopeProfileRead)).Get("/", app.handler.Users.GetProfile)
 
 			// get post
 			r.Group(func(r chi.Router) {
+				r.Use(app.middleware.RequireScope(auth.ScopeProfileRead))
 				r.Use(app.middleware.PostCTXMiddleware)
 				r.Get("/{postID}", app.handler.Post.GetPostByUser)
 			})
 
-			r.Patch("/", app.handler.Users.UpdateUser)
-			r.Put("/image", app.handler.Users.UpdateImages)
+			r.Group(func(r chi.Router) {
+				r.Use(app.middleware.RequireScope(auth.ScopeProfileWrite))
+				r.Patch("/", app.handler.Users.UpdateUser)
+				r.Put("/image", app.handler.Users.UpdateImages)
+			})
+
+			// drafts and scheduled posts, only visible to their author
+			r.Route("/drafts", func(r chi.Router) {
+				r.With(app.middleware.RequireScope(auth.ScopePostsRead)).Get("/", app.handler.Post.GetDrafts)
+				r.With(app.middleware.RequireScope(auth.ScopePostsRead)).Get("/{postID}", app.handler.Post.GetDraft)
+				r.With(app.middleware.RequireScope(auth.ScopePostsWrite)).Patch("/{postID}", app.handler.Post.UpdateDraft)
+				r.With(app.middleware.RequireScope(auth.ScopePostsWrite)).Delete("/{postID}", app.handler.Post.DeleteDraft)
+			})
+
+			// deleted posts, kept until the retention job purges them
+			r.Route("/trash", func(r chi.Router) {
+				r.With(app.middleware.RequireScope(auth.ScopePostsRead)).Get("/", app.handler.Post.GetTrash)
+				r.With(app.middleware.RequireScope(auth.ScopePostsWrite)).Post("/{postID}/restore", app.handler.Post.RestorePost)
+			})
+
+			// account security, not reachable with personal access tokens
+			r.Group(func(r chi.Router) {
+				r.Use(app.middleware.RequireSession)
+				r.Patch("/password", app.handler.Users.ChangePassword)
+				r.Delete("/", app.handler.Users.DeleteAccount)
+				r.Get("/export", app.handler.Users.ExportAccount)
+
+				// two factor authentication
+				r.Route("/mfa/totp", func(r chi.Router) {
+					r.Post("/", app.handler.MFA.EnrollTOTP)
+					r.Post("/confirm", app.handler.MFA.ConfirmTOTP)
+					r.Delete("/", app.handler.MFA.DisableTOTP)
+				})
+
+				// devices the user is logged in on
+				r.Route("/sessions", func(r chi.Router) {
+					r.Get("/", app.handler.Auth.GetSessions)
+					r.Delete("/", app.handler.Auth.RevokeOtherSessions)
+					r.Delete("/{sessionID}", app.handler.Auth.RevokeSession)
+				})
+
+				// linked social login identities
+				r.Route("/identities", func(r chi.Router) {
+					r.Get("/", app.handler.Auth.GetIdentities)
+					r.Post("/oidc", app.handler.Auth.LinkOIDC)
+					r.Post("/oidc/confirm", app.handler.Auth.ConfirmOIDCLink)
+					r.Delete("/{identityID}", app.handler.Auth.UnlinkIdentity)
+				})
 
+				// personal access tokens
+				r.Route("/tokens", func(r chi.Router) {
+					r.Get("/", app.handler.Tokens.GetTokens)
+					r.Post("/", app.handler.Tokens.CreateToken)
+					r.Get("/{tokenID}", app.handler.Tokens.GetToken)
+					r.Patch("/{tokenID}", app.handler.Tokens.UpdateToken)
+					r.Delete("/{tokenID}", app.handler.Tokens.DeleteToken)
+				})
+			})
 		})
 
 		// post handler
 		r.Route("/posts", func(r chi.Router) {
 			r.Use(app.middleware.AuthMiddleware)
-			r.Post("/", app.handler.Post.CreatePost)
+			r.With(app.middleware.RequireScope(auth.ScopePostsWrite)).Post("/", app.handler.Post.CreatePost)
 
 			r.Route("/{postID}", func(r chi.Router) {
 				r.Use(app.middleware.PostCTXMiddleware)
-				r.Get("/", app.handler.Post.GetPostByID)
+				r.With(app.middleware.RequireScope(auth.ScopePostsRead)).Get("/", app.handler.Post.GetPostByID)
 
 				// middleware authorization
-				r.Patch("/", app.handler.Post.CheckOwnerPost("moderator", app.handler.Post.UpdatePost))
-				r.Delete("/", app.handler.Post.CheckOwnerPost("admin", app.handler.Post.DeletePost))
+				r.Group(func(r chi.Router) {
+					r.Use(app.middleware.RequireScope(auth.ScopePostsWrite))
+					r.Patch("/", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.UpdatePost))
+					r.Delete("/", app.handler.Post.CheckOwnerPost(rbac.PostDeleteAny, app.handler.Post.DeletePost))
+				})
+
+				// images, managed by whoever may edit the post
+				r.Route("/images", func(r chi.Router) {
+					r.Use(app.middleware.RequireScope(auth.ScopePostsWrite))
+					r.Post("/", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.AddImages))
+					r.Put("/order", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.ReorderImages))
+					r.Patch("/{imageID}", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.UpdateImage))
+					r.Delete("/{imageID}", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.RemoveImage))
+				})
+
+				// edit history, visible to whoever may edit the post
+				r.Route("/revisions", func(r chi.Router) {
+					r.With(app.middleware.RequireScope(auth.ScopePostsRead)).Get("/", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.GetRevisions))
+					r.With(app.middleware.RequireScope(auth.ScopePostsRead)).Get("/{revision}", app.handler.Post.CheckOwnerPost(rbac.PostUpdateAny, app.handler.Post.GetRevisionDiff))
+					r.With(app.middleware.RequireScope(auth.ScopePostsWrite)).Post("/{revision}/restore", app.handler.Post.CheckOwnerPost(rbac.PostRestoreAny, app.handler.Post.RestoreRevision))
+				})
 			})
 		})
 
@@ -108,24 +240,92 @@ func (app *application) mount() http.Handler {
 			r.Use(app.middleware.AuthMiddleware)
 			r.Route("/{userID}", func(r chi.Router) {
 				r.Use(app.middleware.UserProfileCTXMiddleware)
-				r.Get("/", app.handler.Users.GetUserProfile)
+				r.With(app.middleware.RequireScope(auth.ScopeUsersRead)).Get("/", app.handler.Users.GetUserProfile)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.middleware.RequireScope(auth.ScopeUsersWrite))
+					r.Post("/follow", app.handler.Users.FollowUser)
+					r.Delete("/unfollow", app.handler.Users.UnfollowUser)
+					r.Post("/block", app.handler.Users.BlockUser)
+					r.Delete("/unblock", app.handler.Users.UnblockUser)
+				})
+			})
+		})
+
+		// admin handler, session only so a leaked token can't reach it
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(app.middleware.AuthMiddleware)
+			r.Use(app.middleware.RequireSession)
+			r.With(app.middleware.RequirePermission(rbac.LoginAttemptRead)).Get("/login-attempts", app.handler.Admin.GetLoginAttempts)
+			r.With(app.middleware.RequirePermission(rbac.AuditRead)).Get("/audit", app.handler.Admin.GetAuditEvents)
 
-				r.Post("/follow", app.handler.Users.FollowUser)
-				r.Delete("/unfollow", app.handler.Users.UnfollowUser)
+			r.Route("/users", func(r chi.Router) {
+				r.With(app.middleware.RequirePermission(rbac.UserReadAny)).Get("/", app.handler.Admin.GetUsers)
+				r.With(app.middleware.RequirePermission(rbac.UserReadAny)).Get("/{userID}", app.handler.Admin.GetUser)
+				r.With(app.middleware.RequirePermission(rbac.UserRoleUpdate)).Put("/{userID}/role", app.handler.Admin.ChangeRole)
+				r.With(app.middleware.RequirePermission(rbac.UserBan)).Put("/{userID}/deactivate", app.handler.Admin.DeactivateUser)
+				r.With(app.middleware.RequirePermission(rbac.UserBan)).Put("/{userID}/reactivate", app.handler.Admin.ReactivateUser)
+				r.With(app.middleware.RequirePermission(rbac.UserPasswordReset)).Post("/{userID}/password-reset", app.handler.Admin.ForcePasswordReset)
 			})
+
+			r.Group(func(r chi.Router) {
+				r.Use(app.middleware.RequirePermission(rbac.RoleManage))
+				r.Get("/roles", app.handler.Admin.GetRoles)
+				r.Get("/permissions", app.handler.Admin.GetPermissions)
+				r.Put("/roles/{role}/permissions/{permission}", app.handler.Admin.GrantPermission)
+				r.Delete("/roles/{role}/permissions/{permission}", app.handler.Admin.RevokePermission)
+			})
+		})
+
+		// tag handler
+		r.Route("/tags", func(r chi.Router) {
+			r.Use(app.middleware.AuthMiddleware)
+			r.Use(app.middleware.RequireScope(auth.ScopeFeedsRead))
+			r.Get("/trending", app.handler.Tags.GetTrending)
+			r.Get("/{tag}/posts", app.handler.Tags.GetTagPosts)
 		})
 
+		// search handler
+		r.With(app.middleware.AuthMiddleware, app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/search", app.handler.Search.Search)
+
+		// reaction catalogue
+		r.With(app.middleware.AuthMiddleware, app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/reactions", app.handler.Feed.GetReactions)
+
 		// feed handler
 		r.Route("/feeds", func(r chi.Router) {
 			r.Use(app.middleware.AuthMiddleware)
-			r.Get("/", app.handler.Feed.GetFeeds)
+			r.With(app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/", app.handler.Feed.GetFeeds)
 
 			r.Route("/{postID}", func(r chi.Router) {
 				r.Use(app.middleware.PostCTXMiddleware)
-				r.Get("/", app.handler.Feed.GetFeed)
-				r.Post("/comment", app.handler.Feed.CreateComment)
-				r.Put("/like", app.handler.Feed.LikedFeed)
-				r.Put("/dislike", app.handler.Feed.DisikedFeed)
+				r.With(app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/", app.handler.Feed.GetFeed)
+				r.With(app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/comments", app.handler.Feed.GetComments)
+
+				r.Group(func(r chi.Router) {
+					r.Use(app.middleware.RequireScope(auth.ScopeFeedsWrite))
+					r.Post("/comment", app.handler.Feed.CreateComment)
+					r.Put("/reactions/{kind}", app.handler.Feed.ReactFeed)
+					r.Put("/like", app.handler.Feed.LikedFeed)
+					r.Put("/dislike", app.handler.Feed.DisikedFeed)
+				})
+
+				r.Route("/comments/{commentID}", func(r chi.Router) {
+					r.Use(app.middleware.CommentCTXMiddleware)
+					r.With(app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/replies", app.handler.Feed.GetReplies)
+					r.With(app.middleware.RequireScope(auth.ScopeFeedsRead)).Get("/revisions", app.handler.Feed.CheckOwnerComment(rbac.CommentDeleteAny, app.handler.Feed.GetCommentRevisions))
+
+					r.Group(func(r chi.Router) {
+						r.Use(app.middleware.RequireScope(auth.ScopeFeedsWrite))
+						r.Post("/replies", app.handler.Feed.CreateReply)
+						r.Patch("/", app.handler.Feed.UpdateComment)
+						r.Delete("/", app.handler.Feed.CheckOwnerComment(rbac.CommentDeleteAny, app.handler.Feed.DeleteComment))
+						r.Put("/hide", app.handler.Post.CheckOwnerPost(rbac.CommentDeleteAny, app.handler.Feed.HideComment))
+						r.Delete("/hide", app.handler.Post.CheckOwnerPost(rbac.CommentDeleteAny, app.handler.Feed.UnhideComment))
+						r.Put("/reactions/{kind}", app.handler.Feed.ReactComment)
+						r.Put("/like", app.handler.Feed.LikeComment)
+						r.Put("/dislike", app.handler.Feed.DislikeComment)
+					})
+				})
 			})
 		})
 	})
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// job is a periodic background task. Every replica runs its own jobs, so
+// a job has to be safe to run concurrently with itself.
+type job struct {
+	name     string
+	interval time.Duration
+	run      func(context.Context) error
+}
+
+func (app *application) jobs() []job {
+	return []job{
+		{
+			name:     "purge deleted accounts",
+			interval: app.config.jobs.purgeInterval,
+			run: func(ctx context.Context) error {
+				purged, err := app.service.Accounts.PurgeDeletedAccounts(ctx)
+				if purged > 0 {
+					log.Printf("purged %d deleted accounts", purged)
+				}
+				return err
+			},
+		},
+		{
+			name:     "publish scheduled posts",
+			interval: app.config.jobs.publishInterval,
+			run: func(ctx context.Context) error {
+				published, err := app.service.Post.PublishScheduledPosts(ctx)
+				if published > 0 {
+					log.Printf("published %d scheduled posts", published)
+				}
+				return err
+			},
+		},
+		{
+			name:     "purge deleted posts",
+			interval: app.config.jobs.purgeInterval,
+			run: func(ctx context.Context) error {
+				purged, err := app.service.Post.PurgeDeletedPosts(ctx)
+				if purged > 0 {
+					log.Printf("purged %d deleted posts", purged)
+				}
+				return err
+			},
+		},
+	}
+}
+
+func (app *application) startJobs(ctx context.Context) {
+	for _, j := range app.jobs() {
+		go func(j job) {
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				if err := j.run(ctx); err != nil {
+					log.Printf("job %q failed: %v", j.name, err)
+				}
+
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}(j)
+	}
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"time"
 
@@ -9,7 +10,12 @@ import (
 	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
 	"github.com/ArdiSasongko/SocialNetwork/internal/db"
 	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/mailer"
+	"github.com/ArdiSasongko/SocialNetwork/internal/oidc"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/cldnary"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 	"github.com/joho/godotenv"
 )
 
@@ -31,11 +37,40 @@ func main() {
 			secret: env.GetString("JWT_SECRET", "mostsecretvalue"),
 			iss:    env.GetString("JWT_ISS", "SocialNetwork"),
 			exp:    time.Hour * 24 * 3,
+			// asymmetric keys, JWT_KEYS="kid=path.pem,..." switches signing from HS256
+			keys:      env.GetString("JWT_KEYS", ""),
+			activeKID: env.GetString("JWT_ACTIVE_KID", ""),
 		},
 		cloudinary: cldConfig{
 			url:    env.GetString("CLOUDINARY_URL", ""),
 			folder: env.GetString("CLOUDINARY_FOLDER", ""),
 		},
+		mail: mailConfig{
+			driver:    env.GetString("MAIL_DRIVER", "log"),
+			fromEmail: env.GetString("MAIL_FROM", "no-reply@socialnetwork.local"),
+			logFile:   env.GetString("MAIL_LOG_FILE", ""),
+			smtp: smtpConfig{
+				host:     env.GetString("SMTP_HOST", "localhost"),
+				port:     env.GetInt("SMTP_PORT", 587),
+				username: env.GetString("SMTP_USERNAME", ""),
+				password: env.GetString("SMTP_PASSWORD", ""),
+			},
+		},
+		oidc: oidcConfig{
+			name:         env.GetString("OIDC_PROVIDER_NAME", "oidc"),
+			issuer:       env.GetString("OIDC_ISSUER", ""),
+			clientID:     env.GetString("OIDC_CLIENT_ID", ""),
+			clientSecret: env.GetString("OIDC_CLIENT_SECRET", ""),
+			redirectURL:  env.GetString("OIDC_REDIRECT_URL", "http://localhost:3000/v1/authentication/oidc/callback"),
+		},
+		jobs: jobsConfig{
+			purgeInterval:   time.Minute * time.Duration(env.GetInt("JOB_PURGE_INTERVAL_MINUTES", 60)),
+			publishInterval: time.Second * time.Duration(env.GetInt("JOB_PUBLISH_INTERVAL_SECONDS", 30)),
+		},
+		rbac: rbacConfig{
+			// fallback when a change notification is missed
+			refresh: time.Second * time.Duration(env.GetInt("RBAC_REFRESH_SECONDS", 300)),
+		},
 	}
 
 	// connection to database
@@ -50,11 +85,29 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	auth := auth.NewJWT(
-		cfg.auth.secret,
-		cfg.auth.iss,
-		cfg.auth.iss,
-	)
+	var authenticator auth.Authenticator
+	if cfg.auth.keys != "" {
+		keys, err := auth.LoadSigningKeys(cfg.auth.keys)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+
+		authenticator, err = auth.NewKeySet(
+			cfg.auth.iss,
+			cfg.auth.iss,
+			cfg.auth.activeKID,
+			keys,
+		)
+		if err != nil {
+			log.Fatal(err.Error())
+		}
+	} else {
+		authenticator = auth.NewJWT(
+			cfg.auth.secret,
+			cfg.auth.iss,
+			cfg.auth.iss,
+		)
+	}
 
 	cld, err := cldnary.NewCloudinary(
 		cfg.cloudinary.url,
@@ -65,15 +118,51 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	handler := handlers.NewHandler(conn, auth, *cld)
-	middleware := middlewares.NewMiddleware(conn, auth)
+	var mail mailer.Client
+	switch cfg.mail.driver {
+	case "smtp":
+		mail = mailer.NewSMTP(
+			cfg.mail.smtp.host,
+			cfg.mail.smtp.port,
+			cfg.mail.smtp.username,
+			cfg.mail.smtp.password,
+			cfg.mail.fromEmail,
+		)
+	default:
+		mail = mailer.NewLog(cfg.mail.logFile)
+	}
+
+	// social login is disabled unless an issuer is configured
+	var provider *oidc.Provider
+	if cfg.oidc.issuer != "" {
+		provider = oidc.NewProvider(
+			cfg.oidc.name,
+			cfg.oidc.issuer,
+			cfg.oidc.clientID,
+			cfg.oidc.clientSecret,
+			cfg.oidc.redirectURL,
+		)
+	}
+
+	// roles and permissions are cached in memory, shared by every handler
+	permissions := rbac.NewCache(postgresql.NewStorage(conn).Roles)
+	if err := permissions.Load(context.Background()); err != nil {
+		log.Fatal(err.Error())
+	}
+	go permissions.Listen(context.Background(), cfg.db.addr, cfg.rbac.refresh)
+
+	handler := handlers.NewHandler(conn, authenticator, *cld, mail, provider, permissions)
+	middleware := middlewares.NewMiddleware(conn, authenticator, permissions)
 
 	app := application{
 		config:     cfg,
 		handler:    handler,
 		middleware: middleware,
+		service:    service.NewService(conn, authenticator, *cld, mail, provider, permissions),
 	}
 
+	app.startJobs(context.Background())
+
 	mux := app.mount()
 	log.Fatal(app.run(mux))
 }
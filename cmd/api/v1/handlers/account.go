@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+func (h *UserHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	payload := new(models.DeleteAccountPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Client = getClient(r)
+
+	resp, err := h.service.Accounts.DeleteAccount(r.Context(), user, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPassword):
+			h.error.BadRequestError(w, r, err)
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+// ExportAccount returns everything tied to the user as json, or as a zip
+// with one file per section when format=zip.
+func (h *UserHandler) ExportAccount(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "zip" {
+		h.error.BadRequestError(w, r, fmt.Errorf("format must be json or zip"))
+		return
+	}
+
+	export, err := h.service.Accounts.ExportAccount(r.Context(), user)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	filename := fmt.Sprintf("%s-export-%s", user.Username, time.Now().UTC().Format("20060102"))
+	if format != "zip" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".json"))
+		if err := h.json.WriteJSON(w, http.StatusOK, export); err != nil {
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"posts.json", export.Posts},
+		{"comments.json", export.Comments},
+		{"reactions.json", export.Reactions},
+		{"following.json", export.Following},
+		{"followers.json", export.Followers},
+		{"identities.json", export.Identities},
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename+".zip"))
+	w.WriteHeader(http.StatusOK)
+
+	// headers are out, errors from here on can only be logged
+	archive := zip.NewWriter(w)
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			log.Printf("failed to write export of user %d: %v", user.ID, err)
+			return
+		}
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			log.Printf("failed to write export of user %d: %v", user.ID, err)
+			return
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		log.Printf("failed to write export of user %d: %v", user.ID, err)
+	}
+}
+
+func (h *AuthHandler) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	payload := new(models.RestoreAccountPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Client = getClient(r)
+
+	resp, err := h.service.Auth.RestoreAccount(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			h.error.UnauthorizedError(w, r, err)
+		case errors.Is(err, service.ErrTooManyAttempts):
+			h.error.TooManyRequestsError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
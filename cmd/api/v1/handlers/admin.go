@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/ArdiSasongko/SocialNetwork/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+type AdminHandler struct {
+	service service.Service
+	json    utils.JsonUtils
+	error   utils.ErrorUtils
+}
+
+func (h *AdminHandler) GetLoginAttempts(w http.ResponseWriter, r *http.Request) {
+	pf := postgresql.Pagination{
+		Limit:  10,
+		Offset: 0,
+		Sort:   "desc",
+	}
+
+	pf, err := pf.Parse(r)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := pf.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := postgresql.LoginAttemptFilter{
+		Email:      query.Get("email"),
+		IPAddress:  query.Get("ip"),
+		Result:     query.Get("result"),
+		Pagination: pf,
+	}
+
+	switch filter.Result {
+	case "", postgresql.LoginSuccess, postgresql.LoginFailed, postgresql.LoginLocked:
+	default:
+		h.error.BadRequestError(w, r, fmt.Errorf("result must be one of %s, %s, %s", postgresql.LoginSuccess, postgresql.LoginFailed, postgresql.LoginLocked))
+		return
+	}
+
+	attempts, err := h.service.Admin.GetLoginAttempts(r.Context(), filter)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, attempts); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+// GetAuditEvents pages through the audit log newest first, cursor is the
+// next_cursor of the previous page.
+func (h *AdminHandler) GetAuditEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := postgresql.AuditFilter{
+		Action:     query.Get("action"),
+		TargetType: query.Get("target_type"),
+		Limit:      20,
+	}
+
+	ids := map[string]**int64{
+		"actor_id":  &filter.ActorID,
+		"target_id": &filter.TargetID,
+	}
+	for name, dst := range ids {
+		if value := query.Get(name); value != "" {
+			id, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				h.error.BadRequestError(w, r, fmt.Errorf("%s must be a number", name))
+				return
+			}
+			*dst = &id
+		}
+	}
+
+	times := map[string]**time.Time{
+		"since": &filter.Since,
+		"until": &filter.Until,
+	}
+	for name, dst := range times {
+		if value := query.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				h.error.BadRequestError(w, r, fmt.Errorf("%s must be an RFC 3339 time", name))
+				return
+			}
+			*dst = &t
+		}
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		id, err := strconv.ParseInt(cursor, 10, 64)
+		if err != nil || id < 1 {
+			h.error.BadRequestError(w, r, fmt.Errorf("cursor is invalid"))
+			return
+		}
+		filter.BeforeID = id
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil || l < 1 || l > 100 {
+			h.error.BadRequestError(w, r, fmt.Errorf("limit must be between 1 and 100"))
+			return
+		}
+		filter.Limit = l
+	}
+
+	events, err := h.service.Admin.GetAuditEvents(r.Context(), filter)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, events); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) GetUsers(w http.ResponseWriter, r *http.Request) {
+	pf := postgresql.Pagination{
+		Limit:  10,
+		Offset: 0,
+		Sort:   "desc",
+	}
+
+	pf, err := pf.Parse(r)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := pf.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := postgresql.UserFilter{
+		Search:     query.Get("q"),
+		Role:       query.Get("role"),
+		Pagination: pf,
+	}
+
+	if active := query.Get("active"); active != "" {
+		isActive, err := strconv.ParseBool(active)
+		if err != nil {
+			h.error.BadRequestError(w, r, fmt.Errorf("active must be true or false"))
+			return
+		}
+		filter.Active = &isActive
+	}
+
+	users, err := h.service.Admin.GetUsers(r.Context(), filter)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, users); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	user, err := h.service.Admin.GetUser(r.Context(), userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, user); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) ChangeRole(w http.ResponseWriter, r *http.Request) {
+	actor := getUserfromCtx(r)
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload := new(models.ChangeRolePayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+	payload.Client = getClient(r)
+
+	user, err := h.service.Admin.ChangeRole(r.Context(), actor, userID, payload)
+	if err != nil {
+		h.adminError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, user); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) DeactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, false)
+}
+
+func (h *AdminHandler) ReactivateUser(w http.ResponseWriter, r *http.Request) {
+	h.setActive(w, r, true)
+}
+
+func (h *AdminHandler) setActive(w http.ResponseWriter, r *http.Request, active bool) {
+	actor := getUserfromCtx(r)
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	user, err := h.service.Admin.SetActive(r.Context(), actor, userID, active, getClient(r))
+	if err != nil {
+		h.adminError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, user); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) ForcePasswordReset(w http.ResponseWriter, r *http.Request) {
+	actor := getUserfromCtx(r)
+
+	userID, err := strconv.ParseInt(chi.URLParam(r, "userID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.Admin.ForcePasswordReset(r.Context(), actor, userID, getClient(r))
+	if err != nil {
+		h.adminError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.service.Role.GetRoles(r.Context())
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, roles); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) GetPermissions(w http.ResponseWriter, r *http.Request) {
+	permissions, err := h.service.Role.GetPermissions(r.Context())
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, permissions); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) GrantPermission(w http.ResponseWriter, r *http.Request) {
+	actor := getUserfromCtx(r)
+	role, permission := chi.URLParam(r, "role"), chi.URLParam(r, "permission")
+
+	if err := h.service.Role.GrantPermission(r.Context(), actor, role, permission, getClient(r)); err != nil {
+		h.adminError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) RevokePermission(w http.ResponseWriter, r *http.Request) {
+	actor := getUserfromCtx(r)
+	role, permission := chi.URLParam(r, "role"), chi.URLParam(r, "permission")
+
+	if err := h.service.Role.RevokePermission(r.Context(), actor, role, permission, getClient(r)); err != nil {
+		h.adminError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AdminHandler) adminError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrSelfAction), errors.Is(err, service.ErrUnknownRole), errors.Is(err, service.ErrUnknownPermission):
+		h.error.BadRequestError(w, r, err)
+	case errors.Is(err, service.ErrOutranked):
+		h.error.ForbiddenError(w, r)
+	case errors.Is(err, postgresql.ErrNotFound):
+		h.error.NotFoundError(w, r, err)
+	default:
+		h.error.InternalServerError(w, r, err)
+	}
+}
@@ -2,12 +2,16 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
+	"github.com/ArdiSasongko/SocialNetwork/cmd/api/v1/middlewares"
 	"github.com/ArdiSasongko/SocialNetwork/internal/models"
 	"github.com/ArdiSasongko/SocialNetwork/internal/service"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 	"github.com/ArdiSasongko/SocialNetwork/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 )
 
 type AuthHandler struct {
@@ -47,6 +51,25 @@ func (h *AuthHandler) RegisterUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *AuthHandler) ActivateUser(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+
+	if err := h.service.Auth.ActivateUser(r.Context(), token); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, fmt.Errorf("activation token is invalid or expired"))
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
 func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 	payload := new(models.LoginPayload)
 
@@ -60,11 +83,168 @@ func (h *AuthHandler) LoginUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	payload.Client = getClient(r)
+
 	token, err := h.service.Auth.LoginUser(r.Context(), payload)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCredentials):
+			h.error.UnauthorizedError(w, r, err)
+		case errors.Is(err, service.ErrTooManyAttempts):
+			h.error.TooManyRequestsError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, token); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	payload := new(models.RefreshPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	token, err := h.service.Auth.RefreshToken(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			h.error.UnauthorizedError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, token); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	session := getSessionfromCtx(r)
+
+	if err := h.service.Auth.Logout(r.Context(), session.ID); err != nil {
 		switch {
 		case errors.Is(err, postgresql.ErrNotFound):
-			h.error.NotFoundError(w, r, err)
+			h.error.UnauthorizedError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func getSessionfromCtx(r *http.Request) *postgresql.Session {
+	session, _ := r.Context().Value(middlewares.SessionCtx).(*postgresql.Session)
+	return session
+}
+
+func getClient(r *http.Request) models.Client {
+	return models.Client{
+		IPAddress: middlewares.ClientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+}
+
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	payload := new(models.ForgotPasswordPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Auth.ForgotPassword(r.Context(), payload); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusAccepted, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	payload := new(models.ResetPasswordPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Auth.ResetPassword(r.Context(), payload); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	payload := new(models.MFAVerifyPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Client = getClient(r)
+
+	token, err := h.service.Auth.VerifyMFA(r.Context(), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken),
+			errors.Is(err, service.ErrInvalidMFACode),
+			errors.Is(err, service.ErrMFAAlreadyAccepted),
+			errors.Is(err, service.ErrMFANotEnrolled),
+			errors.Is(err, postgresql.ErrNotFound):
+			h.error.UnauthorizedError(w, r, err)
+		case errors.Is(err, service.ErrTooManyAttempts):
+			h.error.TooManyRequestsError(w, r, err)
 		default:
 			h.error.InternalServerError(w, r, err)
 		}
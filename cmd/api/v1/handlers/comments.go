@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/cmd/api/v1/middlewares"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	defaultComments = 10
+	maxComments     = 50
+	defaultReplies  = 3
+	maxReplies      = 10
+)
+
+// commentPage reads the sort, size and cursor of the page of comments and
+// how many replies to show under each of them.
+func commentPage(r *http.Request, defaultSort string) (postgresql.CommentPage, string, int, error) {
+	query := r.URL.Query()
+
+	page := postgresql.CommentPage{
+		Sort:  defaultSort,
+		Limit: defaultComments,
+	}
+
+	if sort := query.Get("sort"); sort != "" {
+		switch sort {
+		case postgresql.CommentsNewest, postgresql.CommentsOldest, postgresql.CommentsTop:
+			page.Sort = sort
+		default:
+			return page, "", 0, fmt.Errorf("sort must be one of %s, %s or %s", postgresql.CommentsNewest, postgresql.CommentsOldest, postgresql.CommentsTop)
+		}
+	}
+
+	if value := query.Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > maxComments {
+			return page, "", 0, fmt.Errorf("limit must be between 1 and %d", maxComments)
+		}
+		page.Limit = n
+	}
+
+	replies := defaultReplies
+	if value := query.Get("replies"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 || n > maxReplies {
+			return page, "", 0, fmt.Errorf("replies must be between 0 and %d", maxReplies)
+		}
+		replies = n
+	}
+
+	return page, query.Get("cursor"), replies, nil
+}
+
+// GetComments pages through the top level comments, newest first unless
+// asked otherwise.
+func (h *FeedHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+
+	page, cursor, replies, err := commentPage(r, postgresql.CommentsNewest)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	comments, err := h.service.Feeds.GetComments(r.Context(), post.ID, nil, page, cursor, replies)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, comments); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+// GetReplies pages through the replies to a comment, oldest first so they
+// read as a conversation.
+func (h *FeedHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+	comment := getCommentfromCtx(r)
+
+	page, cursor, replies, err := commentPage(r, postgresql.CommentsOldest)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	comments, err := h.service.Feeds.GetComments(r.Context(), post.ID, &comment.ID, page, cursor, replies)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, comments); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *FeedHandler) CreateReply(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+	user := getUserfromCtx(r)
+
+	payload := new(models.CommentPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.UserID = user.ID
+	payload.PostID = post.ID
+
+	reply, err := h.service.Feeds.CreateReply(r.Context(), getCommentfromCtx(r), payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrCommentTooDeep):
+			h.error.BadRequestError(w, r, err)
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusCreated, reply); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *FeedHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	comment := getCommentfromCtx(r)
+
+	// nobody edits someone else's words, moderators hide or delete instead
+	if comment.UserID != user.ID {
+		h.error.ForbiddenError(w, r)
+		return
+	}
+
+	payload := new(models.CommentUpdatePayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.Feeds.UpdateComment(r.Context(), user, comment, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *FeedHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	if err := h.service.Feeds.DeleteComment(r.Context(), getUserfromCtx(r), getCommentfromCtx(r), getClient(r)); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusNoContent, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *FeedHandler) HideComment(w http.ResponseWriter, r *http.Request) {
+	h.setCommentHidden(w, r, true)
+}
+
+func (h *FeedHandler) UnhideComment(w http.ResponseWriter, r *http.Request) {
+	h.setCommentHidden(w, r, false)
+}
+
+func (h *FeedHandler) setCommentHidden(w http.ResponseWriter, r *http.Request, hidden bool) {
+	if err := h.service.Feeds.SetCommentHidden(r.Context(), getUserfromCtx(r), getCommentfromCtx(r), hidden, getClient(r)); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *FeedHandler) ReactComment(w http.ResponseWriter, r *http.Request) {
+	h.reactComment(w, r, chi.URLParam(r, "kind"))
+}
+
+func (h *FeedHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
+	h.reactComment(w, r, postgresql.ReactionLike)
+}
+
+func (h *FeedHandler) DislikeComment(w http.ResponseWriter, r *http.Request) {
+	h.reactComment(w, r, postgresql.ReactionDislike)
+}
+
+func (h *FeedHandler) reactComment(w http.ResponseWriter, r *http.Request, kind string) {
+	post := getPostfromCtx(r)
+	user := getUserfromCtx(r)
+	comment := getCommentfromCtx(r)
+
+	payload := new(models.UserActivitiesPayload)
+	payload.PostID = post.ID
+	payload.UserID = user.ID
+	payload.CommentID = comment.ID
+	payload.Reaction = kind
+
+	if err := h.service.Feeds.ReactComment(r.Context(), comment, payload); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrUnknownReaction):
+			h.error.BadRequestError(w, r, err)
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *FeedHandler) GetCommentRevisions(w http.ResponseWriter, r *http.Request) {
+	revisions, err := h.service.Feeds.GetCommentRevisions(r.Context(), getCommentfromCtx(r))
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, revisions); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func getCommentfromCtx(r *http.Request) *postgresql.Comment {
+	comment, _ := r.Context().Value(middlewares.CommentCtx).(*postgresql.Comment)
+	return comment
+}
+
+// CheckOwnerComment lets the author of the comment and the owner of the post
+// through, anyone else needs the given permission.
+func (h *FeedHandler) CheckOwnerComment(permission string, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		post := getPostfromCtx(r)
+		comment := getCommentfromCtx(r)
+		user := getUserfromCtx(r)
+
+		if user.ID == comment.UserID || user.ID == post.UserID {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		allow, err := h.service.Role.HasPermission(r.Context(), user, permission)
+		if err != nil {
+			h.error.InternalServerError(w, r, err)
+			return
+		}
+
+		if !allow {
+			h.error.ForbiddenError(w, r)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
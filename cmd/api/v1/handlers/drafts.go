@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *PostHandler) GetDrafts(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	drafts, err := h.service.Post.GetDrafts(r.Context(), user.ID)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, drafts); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) GetDraft(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	postID, err := strconv.ParseInt(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	draft, err := h.service.Post.GetDraft(r.Context(), user.ID, postID)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, draft); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) UpdateDraft(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	postID, err := strconv.ParseInt(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload := new(models.DraftUpdatePayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	draft, err := h.service.Post.UpdateDraft(r.Context(), user.ID, postID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPublishAt), errors.Is(err, service.ErrInvalidTags):
+			h.error.BadRequestError(w, r, err)
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, draft); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) DeleteDraft(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	postID, err := strconv.ParseInt(chi.URLParam(r, "postID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Post.DeleteDraft(r.Context(), user.ID, postID); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusNoContent, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
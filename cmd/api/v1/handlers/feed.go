@@ -1,12 +1,14 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/ArdiSasongko/SocialNetwork/internal/models"
 	"github.com/ArdiSasongko/SocialNetwork/internal/service"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 	"github.com/ArdiSasongko/SocialNetwork/utils"
+	"github.com/go-chi/chi/v5"
 )
 
 type FeedHandler struct {
@@ -49,7 +51,7 @@ func (h *FeedHandler) GetFeeds(w http.ResponseWriter, r *http.Request) {
 func (h *FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	post := getPostfromCtx(r)
 
-	feed, err := h.service.Feeds.GetFeed(r.Context(), post.ID)
+	feed, err := h.service.Feeds.GetFeed(r.Context(), post)
 	if err != nil {
 		h.error.InternalServerError(w, r, err)
 		return
@@ -61,35 +63,49 @@ func (h *FeedHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (h *FeedHandler) LikedFeed(w http.ResponseWriter, r *http.Request) {
-	post := getPostfromCtx(r)
-	user := getUserfromCtx(r)
-
-	payload := new(models.UserActivitiesPayload)
-	payload.PostID = post.ID
-	payload.UserID = user.ID
-
-	if err := h.service.Feeds.LikePost(r.Context(), payload); err != nil {
+func (h *FeedHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	reactions, err := h.service.Feeds.GetReactions(r.Context())
+	if err != nil {
 		h.error.InternalServerError(w, r, err)
 		return
 	}
 
-	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+	if err := h.json.JsonResponse(w, http.StatusOK, reactions); err != nil {
 		h.error.InternalServerError(w, r, err)
 		return
 	}
 }
 
+func (h *FeedHandler) ReactFeed(w http.ResponseWriter, r *http.Request) {
+	h.reactPost(w, r, chi.URLParam(r, "kind"))
+}
+
+// LikedFeed and DisikedFeed are kept for older clients, they are the like
+// and dislike reactions.
+func (h *FeedHandler) LikedFeed(w http.ResponseWriter, r *http.Request) {
+	h.reactPost(w, r, postgresql.ReactionLike)
+}
+
 func (h *FeedHandler) DisikedFeed(w http.ResponseWriter, r *http.Request) {
+	h.reactPost(w, r, postgresql.ReactionDislike)
+}
+
+func (h *FeedHandler) reactPost(w http.ResponseWriter, r *http.Request, kind string) {
 	post := getPostfromCtx(r)
 	user := getUserfromCtx(r)
 
 	payload := new(models.UserActivitiesPayload)
 	payload.PostID = post.ID
 	payload.UserID = user.ID
-
-	if err := h.service.Feeds.DislikePost(r.Context(), payload); err != nil {
-		h.error.InternalServerError(w, r, err)
+	payload.Reaction = kind
+
+	if err := h.service.Feeds.ReactPost(r.Context(), payload); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrUnknownReaction):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
 		return
 	}
 
@@ -120,6 +136,7 @@ func (h *FeedHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.service.Feeds.CreateCommentPost(r.Context(), payload); err != nil {
 		h.error.InternalServerError(w, r, err)
+		return
 	}
 
 	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
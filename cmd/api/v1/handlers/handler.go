@@ -5,6 +5,9 @@ import (
 	"net/http"
 
 	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/mailer"
+	"github.com/ArdiSasongko/SocialNetwork/internal/oidc"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
 	"github.com/ArdiSasongko/SocialNetwork/internal/service"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/cldnary"
 	"github.com/ArdiSasongko/SocialNetwork/utils"
@@ -14,6 +17,9 @@ type Handler struct {
 	Health interface {
 		Get(w http.ResponseWriter, r *http.Request)
 	}
+	JWKS interface {
+		Get(w http.ResponseWriter, r *http.Request)
+	}
 	Users interface {
 		GetProfile(w http.ResponseWriter, r *http.Request)
 		UpdateImages(w http.ResponseWriter, r *http.Request)
@@ -21,30 +27,111 @@ type Handler struct {
 		GetUserProfile(w http.ResponseWriter, r *http.Request)
 		FollowUser(w http.ResponseWriter, r *http.Request)
 		UnfollowUser(w http.ResponseWriter, r *http.Request)
+		BlockUser(w http.ResponseWriter, r *http.Request)
+		UnblockUser(w http.ResponseWriter, r *http.Request)
+		ChangePassword(w http.ResponseWriter, r *http.Request)
+		DeleteAccount(w http.ResponseWriter, r *http.Request)
+		ExportAccount(w http.ResponseWriter, r *http.Request)
 	}
 	Auth interface {
 		RegisterUser(w http.ResponseWriter, r *http.Request)
+		ActivateUser(w http.ResponseWriter, r *http.Request)
 		LoginUser(w http.ResponseWriter, r *http.Request)
+		VerifyMFA(w http.ResponseWriter, r *http.Request)
+		RefreshToken(w http.ResponseWriter, r *http.Request)
+		Logout(w http.ResponseWriter, r *http.Request)
+		ForgotPassword(w http.ResponseWriter, r *http.Request)
+		ResetPassword(w http.ResponseWriter, r *http.Request)
+		StartOIDC(w http.ResponseWriter, r *http.Request)
+		OIDCCallback(w http.ResponseWriter, r *http.Request)
+		LinkOIDC(w http.ResponseWriter, r *http.Request)
+		ConfirmOIDCLink(w http.ResponseWriter, r *http.Request)
+		GetIdentities(w http.ResponseWriter, r *http.Request)
+		UnlinkIdentity(w http.ResponseWriter, r *http.Request)
+		GetSessions(w http.ResponseWriter, r *http.Request)
+		RevokeSession(w http.ResponseWriter, r *http.Request)
+		RevokeOtherSessions(w http.ResponseWriter, r *http.Request)
+		RestoreAccount(w http.ResponseWriter, r *http.Request)
+	}
+	MFA interface {
+		EnrollTOTP(w http.ResponseWriter, r *http.Request)
+		ConfirmTOTP(w http.ResponseWriter, r *http.Request)
+		DisableTOTP(w http.ResponseWriter, r *http.Request)
+	}
+	Tokens interface {
+		CreateToken(w http.ResponseWriter, r *http.Request)
+		GetTokens(w http.ResponseWriter, r *http.Request)
+		GetToken(w http.ResponseWriter, r *http.Request)
+		UpdateToken(w http.ResponseWriter, r *http.Request)
+		DeleteToken(w http.ResponseWriter, r *http.Request)
 	}
 	Post interface {
 		CreatePost(w http.ResponseWriter, r *http.Request)
 		GetPostByID(w http.ResponseWriter, r *http.Request)
 		UpdatePost(w http.ResponseWriter, r *http.Request)
 		DeletePost(w http.ResponseWriter, r *http.Request)
+		GetRevisions(w http.ResponseWriter, r *http.Request)
+		GetRevisionDiff(w http.ResponseWriter, r *http.Request)
+		RestoreRevision(w http.ResponseWriter, r *http.Request)
+		GetTrash(w http.ResponseWriter, r *http.Request)
+		RestorePost(w http.ResponseWriter, r *http.Request)
+		GetDrafts(w http.ResponseWriter, r *http.Request)
+		GetDraft(w http.ResponseWriter, r *http.Request)
+		UpdateDraft(w http.ResponseWriter, r *http.Request)
+		DeleteDraft(w http.ResponseWriter, r *http.Request)
+		AddImages(w http.ResponseWriter, r *http.Request)
+		RemoveImage(w http.ResponseWriter, r *http.Request)
+		ReorderImages(w http.ResponseWriter, r *http.Request)
+		UpdateImage(w http.ResponseWriter, r *http.Request)
 		CheckOwnerPost(allowRole string, next http.HandlerFunc) http.HandlerFunc
 		GetPostByUser(w http.ResponseWriter, r *http.Request)
 	}
+	Tags interface {
+		GetTagPosts(w http.ResponseWriter, r *http.Request)
+		GetTrending(w http.ResponseWriter, r *http.Request)
+	}
+	Search interface {
+		Search(w http.ResponseWriter, r *http.Request)
+	}
 	Feed interface {
 		GetFeeds(w http.ResponseWriter, r *http.Request)
 		GetFeed(w http.ResponseWriter, r *http.Request)
+		GetReactions(w http.ResponseWriter, r *http.Request)
+		ReactFeed(w http.ResponseWriter, r *http.Request)
 		LikedFeed(w http.ResponseWriter, r *http.Request)
 		DisikedFeed(w http.ResponseWriter, r *http.Request)
 		CreateComment(w http.ResponseWriter, r *http.Request)
+		GetComments(w http.ResponseWriter, r *http.Request)
+		GetReplies(w http.ResponseWriter, r *http.Request)
+		CreateReply(w http.ResponseWriter, r *http.Request)
+		UpdateComment(w http.ResponseWriter, r *http.Request)
+		DeleteComment(w http.ResponseWriter, r *http.Request)
+		HideComment(w http.ResponseWriter, r *http.Request)
+		UnhideComment(w http.ResponseWriter, r *http.Request)
+		ReactComment(w http.ResponseWriter, r *http.Request)
+		LikeComment(w http.ResponseWriter, r *http.Request)
+		DislikeComment(w http.ResponseWriter, r *http.Request)
+		GetCommentRevisions(w http.ResponseWriter, r *http.Request)
+		CheckOwnerComment(permission string, next http.HandlerFunc) http.HandlerFunc
+	}
+	Admin interface {
+		GetLoginAttempts(w http.ResponseWriter, r *http.Request)
+		GetAuditEvents(w http.ResponseWriter, r *http.Request)
+		GetUsers(w http.ResponseWriter, r *http.Request)
+		GetUser(w http.ResponseWriter, r *http.Request)
+		ChangeRole(w http.ResponseWriter, r *http.Request)
+		DeactivateUser(w http.ResponseWriter, r *http.Request)
+		ReactivateUser(w http.ResponseWriter, r *http.Request)
+		ForcePasswordReset(w http.ResponseWriter, r *http.Request)
+		GetRoles(w http.ResponseWriter, r *http.Request)
+		GetPermissions(w http.ResponseWriter, r *http.Request)
+		GrantPermission(w http.ResponseWriter, r *http.Request)
+		RevokePermission(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func NewHandler(db *sql.DB, auth auth.Authenticator, cld cldnary.ClientCloudinary) Handler {
-	service := service.NewService(db, auth, cld)
+func NewHandler(db *sql.DB, auth auth.Authenticator, cld cldnary.ClientCloudinary, mailer mailer.Client, oidc *oidc.Provider, rbac *rbac.Cache) Handler {
+	service := service.NewService(db, auth, cld, mailer, oidc, rbac)
 	json := utils.NewJsonUtils()
 	error := utils.NewErrorUtils()
 	return Handler{
@@ -52,6 +139,11 @@ func NewHandler(db *sql.DB, auth auth.Authenticator, cld cldnary.ClientCloudinar
 			json:  json,
 			error: error,
 		},
+		JWKS: &jwksHandler{
+			auth:  auth,
+			json:  json,
+			error: error,
+		},
 		Users: &UserHandler{
 			service: service,
 			json:    json,
@@ -62,15 +154,40 @@ func NewHandler(db *sql.DB, auth auth.Authenticator, cld cldnary.ClientCloudinar
 			json:    json,
 			error:   error,
 		},
+		MFA: &MFAHandler{
+			service: service,
+			json:    json,
+			error:   error,
+		},
+		Tokens: &TokenHandler{
+			service: service,
+			json:    json,
+			error:   error,
+		},
 		Post: &PostHandler{
 			service: service,
 			json:    json,
 			error:   error,
 		},
+		Tags: &TagHandler{
+			service: service,
+			json:    json,
+			error:   error,
+		},
+		Search: &SearchHandler{
+			service: service,
+			json:    json,
+			error:   error,
+		},
 		Feed: &FeedHandler{
 			service: service,
 			json:    json,
 			error:   error,
 		},
+		Admin: &AdminHandler{
+			service: service,
+			json:    json,
+			error:   error,
+		},
 	}
 }
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *PostHandler) AddImages(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+	payload := new(models.ImagesPayload)
+
+	if err := r.ParseMultipartForm(10 << 20); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	files, err := extractFiles(r, "images")
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Images = files
+	payload.AltTexts = r.Form["alt_text"]
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Client = getClient(r)
+
+	images, err := h.service.Post.AddImages(r.Context(), getUserfromCtx(r), post, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusCreated, images); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) RemoveImage(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+
+	imageID, err := strconv.ParseInt(chi.URLParam(r, "imageID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Post.RemoveImage(r.Context(), getUserfromCtx(r), post, imageID, getClient(r)); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusNoContent, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) ReorderImages(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+	payload := new(models.ImageOrderPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Client = getClient(r)
+
+	images, err := h.service.Post.ReorderImages(r.Context(), getUserfromCtx(r), post, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrInvalidImageOrder):
+			h.error.BadRequestError(w, r, err)
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, images); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) UpdateImage(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+	payload := new(models.ImageUpdatePayload)
+
+	imageID, err := strconv.ParseInt(chi.URLParam(r, "imageID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	payload.Client = getClient(r)
+
+	if err := h.service.Post.UpdateImage(r.Context(), getUserfromCtx(r), post, imageID, payload); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
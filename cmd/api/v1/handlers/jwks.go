@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/utils"
+)
+
+type jwksHandler struct {
+	auth  auth.Authenticator
+	json  utils.JsonUtils
+	error utils.ErrorUtils
+}
+
+// Get serves the plain jwks document, verifiers expect it without the
+// response envelope.
+func (h *jwksHandler) Get(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", "public, max-age=300")
+
+	if err := h.json.WriteJSON(w, http.StatusOK, h.auth.JWKS()); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
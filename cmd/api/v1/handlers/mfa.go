@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/utils"
+)
+
+type MFAHandler struct {
+	service service.Service
+	json    utils.JsonUtils
+	error   utils.ErrorUtils
+}
+
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	resp, err := h.service.MFA.EnrollTOTP(r.Context(), user)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrMFAAlreadyEnabled):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusCreated, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	payload := new(models.MFACodePayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.MFA.ConfirmTOTP(r.Context(), user, payload)
+	if err != nil {
+		h.mfaError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *MFAHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	payload := new(models.MFACodePayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.MFA.DisableTOTP(r.Context(), user, payload); err != nil {
+		h.mfaError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *MFAHandler) mfaError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidMFACode),
+		errors.Is(err, service.ErrMFAAlreadyAccepted),
+		errors.Is(err, service.ErrMFAAlreadyEnabled),
+		errors.Is(err, service.ErrMFANotEnrolled):
+		h.error.BadRequestError(w, r, err)
+	default:
+		h.error.InternalServerError(w, r, err)
+	}
+}
@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/go-chi/chi/v5"
+)
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcCookiePath  = "/v1/authentication/oidc"
+	// oidcStateMaxAge matches how long the state is kept, in seconds
+	oidcStateMaxAge = 10 * 60
+)
+
+// setOIDCState ties the flow to the browser starting it, the callback only
+// accepts a state that matches this cookie.
+func setOIDCState(w http.ResponseWriter, r *http.Request, state string, maxAge int) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    state,
+		Path:     oidcCookiePath,
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https",
+		// Lax and not Strict, the callback is a top level redirect from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+func (h *AuthHandler) StartOIDC(w http.ResponseWriter, r *http.Request) {
+	resp, err := h.service.Auth.StartOIDC(r.Context(), 0)
+	if err != nil {
+		h.oidcError(w, r, err)
+		return
+	}
+
+	setOIDCState(w, r, resp.State, oidcStateMaxAge)
+
+	// browsers can follow the redirect, api clients read the url from the body
+	if r.URL.Query().Get("redirect") == "true" {
+		http.Redirect(w, r, resp.AuthorizationURL, http.StatusFound)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	payload := &models.OIDCCallbackPayload{
+		Code:   r.URL.Query().Get("code"),
+		State:  r.URL.Query().Get("state"),
+		Client: getClient(r),
+	}
+
+	if cookie, err := r.Cookie(oidcStateCookie); err == nil {
+		payload.BrowserState = cookie.Value
+	}
+	// a state is good for one callback whatever its outcome
+	setOIDCState(w, r, "", -1)
+
+	if errParam := r.URL.Query().Get("error"); errParam != "" {
+		h.error.BadRequestError(w, r, errors.New("identity provider returned error: "+errParam))
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.Auth.OIDCCallback(r.Context(), payload)
+	if err != nil {
+		h.oidcError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) LinkOIDC(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	resp, err := h.service.Auth.StartOIDC(r.Context(), user.ID)
+	if err != nil {
+		h.oidcError(w, r, err)
+		return
+	}
+
+	setOIDCState(w, r, resp.State, oidcStateMaxAge)
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+// ConfirmOIDCLink finishes a link with the link_token of the callback, from
+// the session of the user who started it.
+func (h *AuthHandler) ConfirmOIDCLink(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	payload := new(models.OIDCLinkPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.Auth.ConfirmOIDCLink(r.Context(), user.ID, payload)
+	if err != nil {
+		h.oidcError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) GetIdentities(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	identities, err := h.service.Auth.GetIdentities(r.Context(), user.ID)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, identities); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) UnlinkIdentity(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+
+	identityID, err := strconv.ParseInt(chi.URLParam(r, "identityID"), 10, 64)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Auth.UnlinkIdentity(r.Context(), user.ID, identityID); err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *AuthHandler) oidcError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, service.ErrOIDCDisabled):
+		h.error.NotFoundError(w, r, err)
+	case errors.Is(err, service.ErrInvalidToken):
+		h.error.UnauthorizedError(w, r, err)
+	case errors.Is(err, service.ErrAccountExists),
+		errors.Is(err, service.ErrIdentityLinked),
+		errors.Is(err, service.ErrOIDCEmailRequired),
+		errors.Is(err, postgresql.ErrDuplicateUsername):
+		h.error.BadRequestError(w, r, err)
+	default:
+		h.error.InternalServerError(w, r, err)
+	}
+}
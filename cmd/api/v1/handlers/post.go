@@ -1,10 +1,11 @@
 package handlers
 
 import (
-	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/ArdiSasongko/SocialNetwork/cmd/api/v1/middlewares"
 	"github.com/ArdiSasongko/SocialNetwork/internal/models"
@@ -40,6 +41,18 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	payload.Content = r.FormValue("content")
 	payload.Title = r.FormValue("title")
 	payload.Tags = r.Form["tags"]
+	payload.AltTexts = r.Form["alt_text"]
+	payload.Status = r.FormValue("status")
+	payload.Visibility = r.FormValue("visibility")
+
+	if publishAt := r.FormValue("publish_at"); publishAt != "" {
+		t, err := time.Parse(time.RFC3339, publishAt)
+		if err != nil {
+			h.error.BadRequestError(w, r, fmt.Errorf("publish_at must be an RFC 3339 time"))
+			return
+		}
+		payload.PublishAt = &t
+	}
 
 	if err := payload.Validate(); err != nil {
 		h.error.BadRequestError(w, r, err)
@@ -47,7 +60,12 @@ func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.service.Post.CreatePost(r.Context(), payload); err != nil {
-		h.error.InternalServerError(w, r, err)
+		switch {
+		case errors.Is(err, service.ErrInvalidPublishAt), errors.Is(err, service.ErrInvalidTags):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
 		return
 	}
 
@@ -95,8 +113,15 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Post.UpdatePost(r.Context(), post, payload); err != nil {
-		h.error.InternalServerError(w, r, err)
+	payload.Client = getClient(r)
+
+	if err := h.service.Post.UpdatePost(r.Context(), getUserfromCtx(r), post, payload); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTags):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
 		return
 	}
 
@@ -109,7 +134,7 @@ func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	post := getPostfromCtx(r)
 
-	if err := h.service.Post.DeletePost(r.Context(), post.ID); err != nil {
+	if err := h.service.Post.DeletePost(r.Context(), getUserfromCtx(r), post, getClient(r)); err != nil {
 		h.error.InternalServerError(w, r, err)
 		return
 	}
@@ -125,7 +150,9 @@ func getPostfromCtx(r *http.Request) *postgresql.Post {
 	return user
 }
 
-func (h *PostHandler) CheckOwnerPost(allowRole string, next http.HandlerFunc) http.HandlerFunc {
+// CheckOwnerPost lets the owner of the post through, anyone else needs the
+// given permission.
+func (h *PostHandler) CheckOwnerPost(permission string, next http.HandlerFunc) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		post := getPostfromCtx(r)
 		user := getUserfromCtx(r)
@@ -135,8 +162,7 @@ func (h *PostHandler) CheckOwnerPost(allowRole string, next http.HandlerFunc) ht
 			return
 		}
 
-		// check roles allowed
-		allow, err := h.checkRoleAllowed(r.Context(), user, allowRole)
+		allow, err := h.service.Role.HasPermission(r.Context(), user, permission)
 		if err != nil {
 			h.error.InternalServerError(w, r, err)
 			return
@@ -149,12 +175,3 @@ func (h *PostHandler) CheckOwnerPost(allowRole string, next http.HandlerFunc) ht
 		next.ServeHTTP(w, r)
 	})
 }
-
-func (h *PostHandler) checkRoleAllowed(ctx context.Context, user *postgresql.User, roleRequired string) (bool, error) {
-	role, err := h.service.Role.GetRole(ctx, roleRequired)
-	if err != nil {
-		return false, err
-	}
-
-	return user.Role.Level >= role.Level, nil
-}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *PostHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+
+	revisions, err := h.service.Post.GetRevisions(r.Context(), post.ID)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, revisions); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) GetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+
+	revision, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	diff, err := h.service.Post.GetRevisionDiff(r.Context(), post, revision)
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, diff); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *PostHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	post := getPostfromCtx(r)
+
+	revision, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Post.RestoreRevision(r.Context(), getUserfromCtx(r), post, revision, getClient(r)); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTags):
+			h.error.BadRequestError(w, r, err)
+		case errors.Is(err, postgresql.ErrNotFound):
+			h.error.NotFoundError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/utils"
+)
+
+type SearchHandler struct {
+	service service.Service
+	json    utils.JsonUtils
+	error   utils.ErrorUtils
+}
+
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	payload := &models.SearchPayload{
+		Query:  query.Get("q"),
+		Type:   "posts",
+		Limit:  10,
+		Cursor: query.Get("cursor"),
+	}
+
+	if t := query.Get("type"); t != "" {
+		payload.Type = t
+	}
+
+	if limit := query.Get("limit"); limit != "" {
+		l, err := strconv.Atoi(limit)
+		if err != nil {
+			h.error.BadRequestError(w, r, err)
+			return
+		}
+		payload.Limit = l
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	resp, err := h.service.Search.Search(r.Context(), getUserfromCtx(r).ID, payload)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidCursor):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, resp); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
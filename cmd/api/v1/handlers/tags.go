@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/service"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/ArdiSasongko/SocialNetwork/utils"
+	"github.com/go-chi/chi/v5"
+)
+
+const maxTrendingTags = 50
+
+type TagHandler struct {
+	service service.Service
+	json    utils.JsonUtils
+	error   utils.ErrorUtils
+}
+
+func (h *TagHandler) GetTagPosts(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	pf := postgresql.Pagination{
+		Limit:  10,
+		Offset: 0,
+		Sort:   "desc",
+	}
+
+	pf, err := pf.Parse(r)
+	if err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := pf.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	posts, err := h.service.Tags.GetPosts(r.Context(), user.ID, chi.URLParam(r, "tag"), pf)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, posts); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *TagHandler) GetTrending(w http.ResponseWriter, r *http.Request) {
+	limit := 10
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 || n > maxTrendingTags {
+			h.error.BadRequestError(w, r, fmt.Errorf("limit must be between 1 and %d", maxTrendingTags))
+			return
+		}
+		limit = n
+	}
+
+	tags, err := h.service.Tags.GetTrending(r.Context(), limit)
+	if err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, tags); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/ArdiSasongko/SocialNetwork/cmd/api/v1/middlewares"
@@ -19,7 +20,7 @@ type UserHandler struct {
 func (h *UserHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	user := getUserfromCtx(r)
 
-	userResp, err := h.service.Users.GetProfileByID(r.Context(), user.ID)
+	userResp, err := h.service.Users.GetProfileByID(r.Context(), user.ID, user.ID)
 	if err != nil {
 		h.error.BadRequestError(w, r, err)
 		return
@@ -96,7 +97,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandler) GetUserProfile(w http.ResponseWriter, r *http.Request) {
 	user := getUserProfileCtx(r)
 
-	userResp, err := h.service.Users.GetProfileByID(r.Context(), user.ID)
+	userResp, err := h.service.Users.GetProfileByID(r.Context(), getUserfromCtx(r).ID, user.ID)
 	if err != nil {
 		h.error.BadRequestError(w, r, err)
 		return
@@ -112,7 +113,12 @@ func (h *UserHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
 	toFollow := getUserProfileCtx(r)
 
 	if err := h.service.Users.FollowUser(r.Context(), toFollow.ID, user.ID); err != nil {
-		h.error.BadRequestError(w, r, err)
+		switch {
+		case errors.Is(err, postgresql.ErrBlocked):
+			h.error.ForbiddenError(w, r)
+		default:
+			h.error.BadRequestError(w, r, err)
+		}
 		return
 	}
 
@@ -137,6 +143,67 @@ func (h *UserHandler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (h *UserHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	toBlock := getUserProfileCtx(r)
+
+	if err := h.service.Users.BlockUser(r.Context(), toBlock.ID, user.ID); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusCreated, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *UserHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	toUnblock := getUserProfileCtx(r)
+
+	if err := h.service.Users.UnblockUser(r.Context(), toUnblock.ID, user.ID); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusCreated, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
+func (h *UserHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user := getUserfromCtx(r)
+	session := getSessionfromCtx(r)
+	payload := new(models.ChangePasswordPayload)
+
+	if err := h.json.ReadJSON(w, r, payload); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := payload.Validate(); err != nil {
+		h.error.BadRequestError(w, r, err)
+		return
+	}
+
+	if err := h.service.Users.ChangePassword(r.Context(), user, session.ID, payload); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidPassword):
+			h.error.BadRequestError(w, r, err)
+		default:
+			h.error.InternalServerError(w, r, err)
+		}
+		return
+	}
+
+	if err := h.json.JsonResponse(w, http.StatusOK, nil); err != nil {
+		h.error.InternalServerError(w, r, err)
+		return
+	}
+}
+
 func getUserfromCtx(r *http.Request) *postgresql.User {
 	user, _ := r.Context().Value(middlewares.UserCtx).(*postgresql.User)
 	return user
@@ -6,11 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 	"github.com/ArdiSasongko/SocialNetwork/utils"
 	"github.com/go-chi/chi/v5"
@@ -19,19 +22,29 @@ import (
 
 type userkey string
 type postKey string
+type commentKey string
+type sessionKey string
+type tokenKey string
 
 const UserCtx userkey = "user"
 const PostCtx postKey = "post"
+const CommentCtx commentKey = "comment"
 const UserProfileCtx userkey = "userctx"
+const SessionCtx sessionKey = "session"
+const TokenCtx tokenKey = "token"
+
+// last seen is written at most once per interval to keep requests read only
+const sessionTouchInterval = time.Minute
 
 type Middleware struct {
 	json    utils.JsonUtils
 	errror  utils.ErrorUtils
 	auth    auth.Authenticator
 	storage postgresql.Storage
+	rbac    *rbac.Cache
 }
 
-func NewMiddleware(db *sql.DB, auth auth.Authenticator) Middleware {
+func NewMiddleware(db *sql.DB, auth auth.Authenticator, rbac *rbac.Cache) Middleware {
 	json := utils.NewJsonUtils()
 	error := utils.NewErrorUtils()
 	storage := postgresql.NewStorage(db)
@@ -40,6 +53,7 @@ func NewMiddleware(db *sql.DB, auth auth.Authenticator) Middleware {
 		errror:  error,
 		auth:    auth,
 		storage: storage,
+		rbac:    rbac,
 	}
 }
 
@@ -59,6 +73,11 @@ func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 		}
 
 		token := parts[1]
+		if auth.IsPersonalToken(token) {
+			m.personalTokenAuth(w, r, next, token)
+			return
+		}
+
 		jwtToken, err := m.auth.ValidateToken(token)
 		if err != nil {
 			m.errror.UnauthorizedError(w, r, err)
@@ -73,7 +92,25 @@ func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		sessionID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sid"]), 10, 64)
+		if err != nil {
+			m.errror.UnauthorizedError(w, r, fmt.Errorf("token is not bound to a session"))
+			return
+		}
+
 		ctx := r.Context()
+		// reject tokens whose session has been revoked
+		session, err := m.storage.Sessions.GetByID(ctx, sessionID)
+		if err != nil {
+			m.errror.UnauthorizedError(w, r, err)
+			return
+		}
+
+		if !session.IsActive() || session.UserID != userID {
+			m.errror.UnauthorizedError(w, r, fmt.Errorf("session has been revoked"))
+			return
+		}
+
 		// get user from database
 		user, err := m.storage.Users.GetByID(ctx, userID)
 		if err != nil {
@@ -81,11 +118,118 @@ func (m *Middleware) AuthMiddleware(next http.Handler) http.Handler {
 			return
 		}
 
+		ip := ClientIP(r)
+		if time.Since(session.LastSeenAt) > sessionTouchInterval || session.IPAddress != ip {
+			if err := m.storage.Sessions.TouchSession(ctx, session.ID, ip); err != nil {
+				log.Printf("failed to update last seen of session %d: %v", session.ID, err)
+			}
+		}
+
 		ctx = context.WithValue(ctx, UserCtx, user)
+		ctx = context.WithValue(ctx, SessionCtx, session)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// ClientIP reads the address set by middleware.RealIP, RemoteAddr still has
+// the port when no proxy header was present.
+func ClientIP(r *http.Request) string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+
+	if len(ip) > 64 {
+		ip = ip[:64]
+	}
+
+	return ip
+}
+
+func (m *Middleware) personalTokenAuth(w http.ResponseWriter, r *http.Request, next http.Handler, token string) {
+	ctx := r.Context()
+	pat, err := m.storage.Tokens.GetByHash(ctx, auth.HashToken(token))
+	if err != nil {
+		m.errror.UnauthorizedError(w, r, fmt.Errorf("invalid personal access token"))
+		return
+	}
+
+	if pat.IsExpired() {
+		m.errror.UnauthorizedError(w, r, fmt.Errorf("personal access token has expired"))
+		return
+	}
+
+	user, err := m.storage.Users.GetByID(ctx, pat.UserID)
+	if err != nil {
+		m.errror.UnauthorizedError(w, r, err)
+		return
+	}
+
+	if err := m.storage.Tokens.TouchLastUsed(ctx, pat.ID); err != nil {
+		log.Printf("failed to update last used of token %d: %v", pat.ID, err)
+	}
+
+	ctx = context.WithValue(ctx, UserCtx, user)
+	ctx = context.WithValue(ctx, TokenCtx, pat)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireScope limits requests authenticated with a personal access token to
+// the routes its scopes cover, session tokens are not restricted.
+func (m *Middleware) RequireScope(scope string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pat, ok := r.Context().Value(TokenCtx).(*postgresql.PersonalToken)
+			if ok && !pat.HasScope(scope) {
+				m.errror.ForbiddenError(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireSession rejects personal access tokens, used for account security
+// routes a bot should never reach.
+func (m *Middleware) RequireSession(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(SessionCtx).(*postgresql.Session); !ok {
+			m.errror.ForbiddenError(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequirePermission only lets users whose role is granted the permission
+// through.
+func (m *Middleware) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, _ := r.Context().Value(UserCtx).(*postgresql.User)
+			if user == nil {
+				m.errror.UnauthorizedError(w, r, fmt.Errorf("user is not authenticated"))
+				return
+			}
+
+			allow, err := m.rbac.HasPermission(r.Context(), user.Role.Name, permission)
+			if err != nil {
+				m.errror.InternalServerError(w, r, err)
+				return
+			}
+
+			if !allow {
+				m.errror.ForbiddenError(w, r)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 func (m *Middleware) PostCTXMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		idParam := chi.URLParam(r, "postID")
@@ -96,7 +240,20 @@ func (m *Middleware) PostCTXMiddleware(next http.Handler) http.Handler {
 		}
 
 		ctx := r.Context()
-		post, err := m.storage.Posts.GetPostByID(ctx, postID)
+		user, _ := ctx.Value(UserCtx).(*postgresql.User)
+		if user == nil {
+			m.errror.UnauthorizedError(w, r, fmt.Errorf("user is not authenticated"))
+			return
+		}
+
+		// visibility is enforced here for every route below a post
+		readAny, err := m.rbac.HasPermission(ctx, user.Role.Name, rbac.PostReadAny)
+		if err != nil {
+			m.errror.InternalServerError(w, r, err)
+			return
+		}
+
+		post, err := m.storage.Posts.GetPostByID(ctx, postID, postgresql.Viewer{UserID: user.ID, ReadAny: readAny})
 		if err != nil {
 			switch {
 			case errors.Is(err, postgresql.ErrNotFound):
@@ -113,6 +270,39 @@ func (m *Middleware) PostCTXMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// CommentCTXMiddleware loads a comment of the post PostCTXMiddleware loaded,
+// so whoever can't see the post can't reach its comments either.
+func (m *Middleware) CommentCTXMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		commentID, err := strconv.ParseInt(chi.URLParam(r, "commentID"), 10, 64)
+		if err != nil {
+			m.errror.BadRequestError(w, r, err)
+			return
+		}
+
+		ctx := r.Context()
+		post, _ := ctx.Value(PostCtx).(*postgresql.Post)
+		if post == nil {
+			m.errror.NotFoundError(w, r, postgresql.ErrNotFound)
+			return
+		}
+
+		comment, err := m.storage.Comments.GetByID(ctx, post.ID, commentID)
+		if err != nil {
+			switch {
+			case errors.Is(err, postgresql.ErrNotFound):
+				m.errror.NotFoundError(w, r, err)
+			default:
+				m.errror.InternalServerError(w, r, err)
+			}
+			return
+		}
+
+		ctx = context.WithValue(ctx, CommentCtx, comment)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (m *Middleware) UserProfileCTXMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		idParam := chi.URLParam(r, "userID")
@@ -0,0 +1,13 @@
+create table if not exists sessions(
+    id serial primary key,
+    user_id int not null,
+    token_hash bytea not null unique,
+    previous_token_hash bytea,
+    expires_at timestamp(0) with time zone not null,
+    revoked_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone not null default now(),
+    updated_at timestamp(0) with time zone not null default now(),
+    constraint fk_sessions_user_id foreign key (user_id) references users(id) on delete cascade
+);
+
+create index if not exists idx_sessions_user_id on sessions(user_id);
\ No newline at end of file
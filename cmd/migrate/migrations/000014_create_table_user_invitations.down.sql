@@ -0,0 +1 @@
+drop table if exists user_invitations;
\ No newline at end of file
@@ -0,0 +1,6 @@
+create table if not exists user_invitations(
+    token bytea primary key,
+    user_id int not null,
+    expiry timestamp(0) with time zone not null,
+    constraint fk_user_invitations_user_id foreign key (user_id) references users(id) on delete cascade
+);
\ No newline at end of file
@@ -0,0 +1 @@
+drop table if exists password_resets;
\ No newline at end of file
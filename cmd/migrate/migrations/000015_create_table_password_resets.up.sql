@@ -0,0 +1,8 @@
+create table if not exists password_resets(
+    token_hash bytea primary key,
+    user_id int not null,
+    expiry timestamp(0) with time zone not null,
+    used_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_password_resets_user_id foreign key (user_id) references users(id) on delete cascade
+);
\ No newline at end of file
@@ -0,0 +1,3 @@
+drop table if exists mfa_recovery_codes;
+
+drop table if exists user_mfa;
\ No newline at end of file
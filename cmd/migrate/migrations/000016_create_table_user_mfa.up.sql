@@ -0,0 +1,19 @@
+create table if not exists user_mfa(
+    user_id int primary key,
+    secret varchar(64) not null,
+    enabled_at timestamp(0) with time zone,
+    last_used_step bigint not null default 0,
+    created_at timestamp(0) with time zone not null default now(),
+    updated_at timestamp(0) with time zone not null default now(),
+    constraint fk_user_mfa_user_id foreign key (user_id) references users(id) on delete cascade
+);
+
+create table if not exists mfa_recovery_codes(
+    id serial primary key,
+    user_id int not null,
+    code_hash bytea not null,
+    used_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_mfa_recovery_codes_user_id foreign key (user_id) references users(id) on delete cascade,
+    constraint unique_user_recovery_code unique (user_id, code_hash)
+);
\ No newline at end of file
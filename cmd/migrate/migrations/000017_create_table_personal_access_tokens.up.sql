@@ -0,0 +1,13 @@
+create table if not exists personal_access_tokens(
+    id serial primary key,
+    user_id int not null,
+    name varchar(100) not null,
+    token_hash bytea not null unique,
+    scopes varchar(64)[] not null default '{}',
+    expires_at timestamp(0) with time zone,
+    last_used_at timestamp(0) with time zone,
+    created_at timestamp(0) with time zone not null default now(),
+    updated_at timestamp(0) with time zone not null default now(),
+    constraint fk_personal_access_tokens_user_id foreign key (user_id) references users(id) on delete cascade,
+    constraint unique_user_token_name unique (user_id, name)
+);
\ No newline at end of file
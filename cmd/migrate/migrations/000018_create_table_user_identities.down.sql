@@ -0,0 +1,3 @@
+drop table if exists oidc_states;
+
+drop table if exists user_identities;
\ No newline at end of file
@@ -0,0 +1,21 @@
+create table if not exists user_identities(
+    id serial primary key,
+    user_id int not null,
+    provider varchar(64) not null,
+    subject varchar(255) not null,
+    email varchar(255),
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_user_identities_user_id foreign key (user_id) references users(id) on delete cascade,
+    constraint unique_provider_subject unique (provider, subject),
+    constraint unique_user_provider unique (user_id, provider)
+);
+
+create table if not exists oidc_states(
+    state varchar(64) primary key,
+    code_verifier varchar(128) not null,
+    nonce varchar(64) not null,
+    link_user_id int,
+    expires_at timestamp(0) with time zone not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_oidc_states_link_user_id foreign key (link_user_id) references users(id) on delete cascade
+);
\ No newline at end of file
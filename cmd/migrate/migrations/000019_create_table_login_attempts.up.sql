@@ -0,0 +1,13 @@
+create table if not exists login_attempts(
+    id bigserial primary key,
+    user_id int,
+    email varchar(255) not null,
+    ip_address varchar(64) not null,
+    user_agent varchar(512) not null default '',
+    result varchar(32) not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_login_attempts_user_id foreign key (user_id) references users(id) on delete set null
+);
+
+create index if not exists idx_login_attempts_email on login_attempts (email, created_at);
+create index if not exists idx_login_attempts_ip_address on login_attempts (ip_address, created_at);
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table sessions
+    drop column if exists user_agent,
+    drop column if exists ip_address,
+    drop column if exists last_seen_at;
\ No newline at end of file
@@ -0,0 +1,4 @@
+alter table sessions
+    add column if not exists user_agent varchar(512) not null default '',
+    add column if not exists ip_address varchar(64) not null default '',
+    add column if not exists last_seen_at timestamp(0) with time zone not null default now();
\ No newline at end of file
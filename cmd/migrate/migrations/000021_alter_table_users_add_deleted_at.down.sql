@@ -0,0 +1,12 @@
+alter table follows
+    drop constraint if exists fk_follows_user_id,
+    drop constraint if exists fk_follows_follower_id;
+
+alter table user_activities
+    drop constraint if exists fk_user_activities_user_id,
+    drop constraint if exists fk_user_activities_post_id,
+    add constraint fk_user_activities_user_id foreign key (user_id) references users(id),
+    add constraint fk_user_activities_post_id foreign key (post_id) references posts(id);
+
+alter table users
+    drop column if exists deleted_at;
\ No newline at end of file
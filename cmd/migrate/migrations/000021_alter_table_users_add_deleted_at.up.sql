@@ -0,0 +1,16 @@
+alter table users
+    add column if not exists deleted_at timestamp(0) with time zone;
+
+alter table user_activities
+    drop constraint if exists fk_user_activities_user_id,
+    drop constraint if exists fk_user_activities_post_id,
+    add constraint fk_user_activities_user_id foreign key (user_id) references users(id) on delete cascade,
+    add constraint fk_user_activities_post_id foreign key (post_id) references posts(id) on delete cascade;
+
+delete from follows
+where user_id not in (select id from users)
+    or follower_id not in (select id from users);
+
+alter table follows
+    add constraint fk_follows_user_id foreign key (user_id) references users(id) on delete cascade,
+    add constraint fk_follows_follower_id foreign key (follower_id) references users(id) on delete cascade;
\ No newline at end of file
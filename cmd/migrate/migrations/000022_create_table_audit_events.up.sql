@@ -0,0 +1,15 @@
+create table if not exists audit_events(
+    id bigserial primary key,
+    actor_id int,
+    action varchar(64) not null,
+    target_type varchar(32) not null,
+    target_id bigint not null,
+    before jsonb,
+    after jsonb,
+    ip_address varchar(64) not null default '',
+    request_id varchar(128) not null default '',
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_audit_events_actor_id foreign key (actor_id) references users(id) on delete set null
+);
+
+create index if not exists idx_audit_events_target on audit_events (target_type, target_id);
\ No newline at end of file
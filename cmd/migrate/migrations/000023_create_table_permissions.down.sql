@@ -0,0 +1,6 @@
+drop trigger if exists trg_role_permissions_rbac_changed on role_permissions;
+drop trigger if exists trg_permissions_rbac_changed on permissions;
+drop trigger if exists trg_roles_rbac_changed on roles;
+drop function if exists notify_rbac_changed();
+drop table if exists role_permissions;
+drop table if exists permissions;
\ No newline at end of file
@@ -0,0 +1,62 @@
+create table if not exists permissions(
+    id serial primary key,
+    name varchar(64) not null unique,
+    description text not null
+);
+
+create table if not exists role_permissions(
+    role_id int not null,
+    permission_id int not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint pk_role_permissions primary key (role_id, permission_id),
+    constraint fk_role_permissions_role_id foreign key (role_id) references roles(id) on delete cascade,
+    constraint fk_role_permissions_permission_id foreign key (permission_id) references permissions(id) on delete cascade
+);
+
+insert into permissions(name, description)
+values
+    ('post.update.any', 'update posts of other users'),
+    ('post.delete.any', 'delete posts of other users'),
+    ('comment.delete.any', 'delete comments of other users'),
+    ('user.read.any', 'list and view any account, including deactivated ones'),
+    ('user.ban', 'deactivate and reactivate accounts'),
+    ('user.role.update', 'change the role of a user'),
+    ('user.password.reset', 'force a password reset on a user'),
+    ('login_attempt.read', 'view login attempts'),
+    ('role.manage', 'grant and revoke permissions of roles')
+on conflict (name) do nothing;
+
+-- keeps what the role levels allowed before, moderators update and admins do anything
+insert into role_permissions(role_id, permission_id)
+select r.id, p.id
+from roles r
+join permissions p on p.name in ('post.update.any', 'comment.delete.any')
+where r.name = 'moderator'
+on conflict do nothing;
+
+insert into role_permissions(role_id, permission_id)
+select r.id, p.id
+from roles r
+cross join permissions p
+where r.name = 'admin'
+on conflict do nothing;
+
+-- every replica caches roles and permissions, tell them when something changes
+create or replace function notify_rbac_changed() returns trigger as $$
+begin
+    perform pg_notify('rbac_changed', tg_table_name);
+    return null;
+end;
+$$ language plpgsql;
+
+create trigger trg_roles_rbac_changed
+after insert or update or delete on roles
+for each statement execute function notify_rbac_changed();
+
+create trigger trg_permissions_rbac_changed
+after insert or update or delete on permissions
+for each statement execute function notify_rbac_changed();
+
+create trigger trg_role_permissions_rbac_changed
+after insert or update or delete on role_permissions
+for each statement execute function notify_rbac_changed();
\ No newline at end of file
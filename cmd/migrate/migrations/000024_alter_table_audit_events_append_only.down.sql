@@ -0,0 +1,5 @@
+delete from permissions where name = 'audit.read';
+drop index if exists idx_audit_events_action;
+drop index if exists idx_audit_events_actor_id;
+drop trigger if exists trg_audit_events_append_only on audit_events;
+drop function if exists prevent_audit_events_change();
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- audit events are append only, the only change allowed is the foreign key
+-- clearing the actor when their account is purged
+create or replace function prevent_audit_events_change() returns trigger as $$
+begin
+    if tg_op = 'UPDATE' and new.actor_id is null
+        and (new.id, new.action, new.target_type, new.target_id, new.before, new.after, new.ip_address, new.request_id, new.created_at)
+        is not distinct from
+        (old.id, old.action, old.target_type, old.target_id, old.before, old.after, old.ip_address, old.request_id, old.created_at)
+    then
+        return new;
+    end if;
+
+    raise exception 'audit_events is append only';
+end;
+$$ language plpgsql;
+
+create trigger trg_audit_events_append_only
+before update or delete on audit_events
+for each row execute function prevent_audit_events_change();
+
+create index if not exists idx_audit_events_actor_id on audit_events (actor_id, id);
+create index if not exists idx_audit_events_action on audit_events (action, id);
+
+insert into permissions(name, description)
+values ('audit.read', 'view the audit log')
+on conflict (name) do nothing;
+
+insert into role_permissions(role_id, permission_id)
+select r.id, p.id
+from roles r
+join permissions p on p.name = 'audit.read'
+where r.name = 'admin'
+on conflict do nothing;
\ No newline at end of file
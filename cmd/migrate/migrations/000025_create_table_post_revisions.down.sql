@@ -0,0 +1,2 @@
+delete from permissions where name = 'post.restore.any';
+drop table if exists post_revisions;
\ No newline at end of file
@@ -0,0 +1,25 @@
+create table if not exists post_revisions(
+    id bigserial primary key,
+    post_id int not null,
+    revision int not null,
+    title varchar(255) not null,
+    content text not null,
+    tags varchar(255)[],
+    edited_by int,
+    version_created_at timestamp(0) with time zone not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_post_revisions_post_id foreign key (post_id) references posts(id) on delete cascade,
+    constraint fk_post_revisions_edited_by foreign key (edited_by) references users(id) on delete set null,
+    constraint uq_post_revisions_post_revision unique (post_id, revision)
+);
+
+insert into permissions(name, description)
+values ('post.restore.any', 'restore old revisions of posts of other users')
+on conflict (name) do nothing;
+
+insert into role_permissions(role_id, permission_id)
+select r.id, p.id
+from roles r
+join permissions p on p.name = 'post.restore.any'
+where r.name = 'admin'
+on conflict do nothing;
\ No newline at end of file
@@ -0,0 +1,6 @@
+drop index if exists idx_posts_deleted_at;
+
+alter table posts
+drop constraint if exists fk_posts_deleted_by,
+drop column if exists deleted_by,
+drop column if exists deleted_at;
\ No newline at end of file
@@ -0,0 +1,6 @@
+alter table posts
+add column deleted_at timestamp(0) with time zone,
+add column deleted_by int,
+add constraint fk_posts_deleted_by foreign key (deleted_by) references users(id) on delete set null;
+
+create index if not exists idx_posts_deleted_at on posts (deleted_at) where deleted_at is not null;
\ No newline at end of file
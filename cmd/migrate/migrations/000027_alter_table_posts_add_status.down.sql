@@ -0,0 +1,9 @@
+drop index if exists idx_posts_user_status;
+drop index if exists idx_posts_scheduled;
+
+alter table posts
+drop constraint if exists chk_posts_publish_at,
+drop constraint if exists chk_posts_status,
+drop column if exists published_at,
+drop column if exists publish_at,
+drop column if exists status;
\ No newline at end of file
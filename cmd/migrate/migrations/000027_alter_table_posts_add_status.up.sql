@@ -0,0 +1,12 @@
+alter table posts
+add column status varchar(16) not null default 'published',
+add column publish_at timestamp(0) with time zone,
+add column published_at timestamp(0) with time zone,
+add constraint chk_posts_status check (status in ('draft', 'scheduled', 'published')),
+add constraint chk_posts_publish_at check (status <> 'scheduled' or publish_at is not null);
+
+update posts
+set published_at = created_at;
+
+create index if not exists idx_posts_scheduled on posts (publish_at) where status = 'scheduled';
+create index if not exists idx_posts_user_status on posts (user_id, status);
\ No newline at end of file
@@ -0,0 +1,7 @@
+delete from permissions where name = 'post.read.any';
+drop index if exists idx_follows_follower_id;
+drop table if exists post_mentions;
+
+alter table posts
+drop constraint if exists chk_posts_visibility,
+drop column if exists visibility;
\ No newline at end of file
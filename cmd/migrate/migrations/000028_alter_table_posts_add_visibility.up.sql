@@ -0,0 +1,25 @@
+alter table posts
+add column visibility varchar(16) not null default 'public',
+add constraint chk_posts_visibility check (visibility in ('public', 'followers', 'mentioned', 'private'));
+
+create table if not exists post_mentions(
+    post_id int not null,
+    user_id int not null,
+    constraint pk_post_mentions primary key (post_id, user_id),
+    constraint fk_post_mentions_post_id foreign key (post_id) references posts(id) on delete cascade,
+    constraint fk_post_mentions_user_id foreign key (user_id) references users(id) on delete cascade
+);
+
+create index if not exists idx_post_mentions_user_id on post_mentions (user_id);
+create index if not exists idx_follows_follower_id on follows (follower_id, user_id);
+
+insert into permissions(name, description)
+values ('post.read.any', 'open any post regardless of its visibility')
+on conflict (name) do nothing;
+
+insert into role_permissions(role_id, permission_id)
+select r.id, p.id
+from roles r
+join permissions p on p.name = 'post.read.any'
+where r.name in ('moderator', 'admin')
+on conflict do nothing;
\ No newline at end of file
@@ -0,0 +1,8 @@
+drop index if exists idx_images_post_post_id_position;
+
+alter table images_post
+drop constraint if exists pk_images_post,
+drop column if exists alt_text,
+drop column if exists public_id,
+drop column if exists position,
+drop column if exists id;
\ No newline at end of file
@@ -0,0 +1,17 @@
+alter table images_post
+add column id bigserial,
+add column position int not null default 0,
+add column public_id varchar(255),
+add column alt_text varchar(500) not null default '',
+add constraint pk_images_post primary key (id);
+
+-- keep the order images were uploaded in
+update images_post ip
+set position = o.position
+from (
+    select id, row_number() over (partition by post_id order by created_at, id) - 1 as position
+    from images_post
+) o
+where o.id = ip.id;
+
+create index if not exists idx_images_post_post_id_position on images_post (post_id, position);
\ No newline at end of file
@@ -0,0 +1,3 @@
+drop index if exists idx_posts_published_at;
+drop table if exists post_tags;
+drop table if exists tags;
\ No newline at end of file
@@ -0,0 +1,43 @@
+create table if not exists tags (
+    id bigserial primary key,
+    name varchar(50) not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint uq_tags_name unique (name),
+    constraint chk_tags_name check (char_length(name) > 0 and name = lower(name))
+);
+
+create table if not exists post_tags (
+    post_id int not null,
+    tag_id bigint not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint pk_post_tags primary key (post_id, tag_id),
+    constraint fk_post_tags_post_id foreign key (post_id) references posts(id) on delete cascade,
+    constraint fk_post_tags_tag_id foreign key (tag_id) references tags(id) on delete cascade
+);
+
+create index if not exists idx_post_tags_tag_id on post_tags (tag_id, post_id);
+create index if not exists idx_posts_published_at on posts (published_at) where status = 'published';
+
+-- normalize the existing tags the way the api does: trim, strip #, lower case
+update posts p
+set tags = coalesce((
+    select array_agg(n.name order by n.ord)
+    from (
+        select left(lower(btrim(ltrim(btrim(t), '#'))), 50) as name, min(ord) as ord
+        from unnest(p.tags) with ordinality as u(t, ord)
+        group by 1
+    ) n
+    where n.name <> ''
+), '{}')
+where p.tags is not null;
+
+insert into tags (name)
+select distinct unnest(tags)
+from posts
+on conflict (name) do nothing;
+
+insert into post_tags (post_id, tag_id, created_at)
+select p.id, t.id, p.created_at
+from posts p
+join tags t on t.name = any(p.tags)
+on conflict do nothing;
\ No newline at end of file
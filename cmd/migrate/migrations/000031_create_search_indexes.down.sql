@@ -0,0 +1,7 @@
+drop index if exists idx_tags_name_trgm;
+drop index if exists idx_users_fullname_trgm;
+drop index if exists idx_users_username_trgm;
+drop index if exists idx_posts_search_vector;
+
+alter table posts
+drop column if exists search_vector;
\ No newline at end of file
@@ -0,0 +1,13 @@
+create extension if not exists pg_trgm;
+
+-- the simple configuration doesn't stem, posts are not all in one language
+alter table posts
+add column search_vector tsvector generated always as (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(content, '')), 'B')
+) stored;
+
+create index if not exists idx_posts_search_vector on posts using gin (search_vector);
+create index if not exists idx_users_username_trgm on users using gin (username gin_trgm_ops);
+create index if not exists idx_users_fullname_trgm on users using gin (fullname gin_trgm_ops);
+create index if not exists idx_tags_name_trgm on tags using gin (name gin_trgm_ops);
\ No newline at end of file
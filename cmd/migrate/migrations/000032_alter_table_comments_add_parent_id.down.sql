@@ -0,0 +1,8 @@
+drop index if exists idx_comments_parent_id;
+drop index if exists idx_comments_post_top_level;
+
+alter table comments
+drop constraint if exists chk_comments_depth,
+drop constraint if exists fk_comments_parent_id,
+drop column if exists depth,
+drop column if exists parent_id;
\ No newline at end of file
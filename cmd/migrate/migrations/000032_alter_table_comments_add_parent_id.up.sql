@@ -0,0 +1,8 @@
+alter table comments
+add column parent_id int,
+add column depth int not null default 0,
+add constraint fk_comments_parent_id foreign key (parent_id) references comments(id) on delete cascade,
+add constraint chk_comments_depth check ((parent_id is null) = (depth = 0));
+
+create index if not exists idx_comments_post_top_level on comments (post_id, created_at, id) where parent_id is null;
+create index if not exists idx_comments_parent_id on comments (parent_id, created_at, id);
\ No newline at end of file
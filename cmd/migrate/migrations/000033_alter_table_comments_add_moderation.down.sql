@@ -0,0 +1,9 @@
+drop table if exists comment_revisions;
+
+alter table comments
+drop constraint if exists fk_comments_hidden_by,
+drop constraint if exists fk_comments_deleted_by,
+drop column if exists hidden_by,
+drop column if exists hidden_at,
+drop column if exists deleted_by,
+drop column if exists deleted_at;
\ No newline at end of file
@@ -0,0 +1,20 @@
+alter table comments
+add column deleted_at timestamp(0) with time zone,
+add column deleted_by int,
+add column hidden_at timestamp(0) with time zone,
+add column hidden_by int,
+add constraint fk_comments_deleted_by foreign key (deleted_by) references users(id) on delete set null,
+add constraint fk_comments_hidden_by foreign key (hidden_by) references users(id) on delete set null;
+
+create table if not exists comment_revisions(
+    id bigserial primary key,
+    comment_id int not null,
+    content text not null,
+    edited_by int,
+    version_created_at timestamp(0) with time zone not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint fk_comment_revisions_comment_id foreign key (comment_id) references comments(id) on delete cascade,
+    constraint fk_comment_revisions_edited_by foreign key (edited_by) references users(id) on delete set null
+);
+
+create index if not exists idx_comment_revisions_comment_id on comment_revisions (comment_id, id);
\ No newline at end of file
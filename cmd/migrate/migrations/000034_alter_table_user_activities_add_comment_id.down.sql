@@ -0,0 +1,10 @@
+drop index if exists idx_user_activities_comment_id;
+
+delete from user_activities where comment_id is not null;
+
+alter table user_activities
+drop constraint if exists unique_user_comment,
+drop constraint if exists chk_user_activities_target,
+drop constraint if exists fk_user_activities_comment_id,
+drop column if exists comment_id,
+alter column post_id set not null;
\ No newline at end of file
@@ -0,0 +1,8 @@
+alter table user_activities
+alter column post_id drop not null,
+add column comment_id int,
+add constraint fk_user_activities_comment_id foreign key (comment_id) references comments(id) on delete cascade,
+add constraint chk_user_activities_target check (num_nonnulls(post_id, comment_id) = 1),
+add constraint unique_user_comment unique (user_id, comment_id);
+
+create index if not exists idx_user_activities_comment_id on user_activities (comment_id) where comment_id is not null;
\ No newline at end of file
@@ -0,0 +1,18 @@
+drop index if exists idx_user_activities_post_id_reaction;
+
+alter table user_activities
+add column is_liked boolean,
+add column is_disliked boolean;
+
+update user_activities
+set is_liked = reaction = 'like',
+    is_disliked = reaction = 'dislike';
+
+-- the other reactions have no place in the old columns
+delete from user_activities where not is_liked and not is_disliked;
+
+alter table user_activities
+drop constraint if exists fk_user_activities_reaction,
+drop column if exists reaction;
+
+drop table if exists reactions;
\ No newline at end of file
@@ -0,0 +1,37 @@
+create table if not exists reactions(
+    kind varchar(20) primary key,
+    emoji text not null,
+    position int not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint chk_reactions_kind check (kind ~ '^[a-z_]+$')
+);
+
+insert into reactions (kind, emoji, position) values
+    ('like', '👍', 1),
+    ('love', '❤️', 2),
+    ('laugh', '😂', 3),
+    ('wow', '😮', 4),
+    ('sad', '😢', 5),
+    ('angry', '😠', 6),
+    ('dislike', '👎', 7)
+on conflict (kind) do nothing;
+
+alter table user_activities
+add column reaction varchar(20);
+
+update user_activities
+set reaction = case
+    when is_liked then 'like'
+    when is_disliked then 'dislike'
+end;
+
+-- rows toggled back off carry no reaction
+delete from user_activities where reaction is null;
+
+alter table user_activities
+alter column reaction set not null,
+add constraint fk_user_activities_reaction foreign key (reaction) references reactions(kind) on update cascade,
+drop column is_liked,
+drop column is_disliked;
+
+create index if not exists idx_user_activities_post_id_reaction on user_activities (post_id, reaction) where post_id is not null;
\ No newline at end of file
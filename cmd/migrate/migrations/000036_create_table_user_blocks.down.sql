@@ -0,0 +1 @@
+drop table if exists user_blocks;
\ No newline at end of file
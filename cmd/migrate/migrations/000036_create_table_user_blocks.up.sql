@@ -0,0 +1,12 @@
+create table if not exists user_blocks(
+    blocker_id int not null,
+    blocked_id int not null,
+    created_at timestamp(0) with time zone not null default now(),
+    constraint pk_user_blocks primary key (blocker_id, blocked_id),
+    constraint fk_user_blocks_blocker_id foreign key (blocker_id) references users(id) on delete cascade,
+    constraint fk_user_blocks_blocked_id foreign key (blocked_id) references users(id) on delete cascade,
+    constraint chk_user_blocks_self check (blocker_id <> blocked_id)
+);
+
+-- blocks are checked from both sides
+create index if not exists idx_user_blocks_blocked_id on user_blocks (blocked_id, blocker_id);
\ No newline at end of file
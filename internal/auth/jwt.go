@@ -41,3 +41,8 @@ func (a *JWTAuthenticator) ValidateToken(token string) (*jwt.Token, error) {
 		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Name}),
 	)
 }
+
+// JWKS is always empty, a symmetric secret must never be published.
+func (a *JWTAuthenticator) JWKS() JWKSet {
+	return JWKSet{Keys: []JWK{}}
+}
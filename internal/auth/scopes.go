@@ -0,0 +1,53 @@
+package auth
+
+import "strings"
+
+// PersonalTokenPrefix marks personal access tokens so they can be told apart
+// from jwt access tokens without a database lookup.
+const PersonalTokenPrefix = "snp_"
+
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+	ScopePostsRead    = "posts:read"
+	ScopePostsWrite   = "posts:write"
+	ScopeFeedsRead    = "feeds:read"
+	ScopeFeedsWrite   = "feeds:write"
+	ScopeUsersRead    = "users:read"
+	ScopeUsersWrite   = "users:write"
+)
+
+var Scopes = []string{
+	ScopeProfileRead,
+	ScopeProfileWrite,
+	ScopePostsRead,
+	ScopePostsWrite,
+	ScopeFeedsRead,
+	ScopeFeedsWrite,
+	ScopeUsersRead,
+	ScopeUsersWrite,
+}
+
+func IsValidScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+func NewPersonalToken() (string, []byte, error) {
+	token, _, err := NewOpaqueToken()
+	if err != nil {
+		return "", nil, err
+	}
+
+	token = PersonalTokenPrefix + token
+	return token, HashToken(token), nil
+}
+
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
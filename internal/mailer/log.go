@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogMailer is meant for local development, it writes every email to the
+// application log or, when a path is given, appends it to that file.
+type LogMailer struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewLog(path string) *LogMailer {
+	return &LogMailer{
+		path: path,
+	}
+}
+
+func (m *LogMailer) Send(templateFile, username, email string, data any) error {
+	msg, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	if m.path == "" {
+		log.Printf("email to %v <%v>\nsubject: %v\n%v", username, email, msg.Subject, msg.Body)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "date: %v\nto: %v <%v>\nsubject: %v\n\n%v\n---\n", time.Now().Format(time.RFC3339), username, email, msg.Subject, msg.Body)
+	return err
+}
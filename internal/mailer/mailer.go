@@ -0,0 +1,48 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"text/template"
+)
+
+const (
+	UserInvitationTemplate      = "user_invitation.tmpl"
+	PasswordResetTemplate       = "password_reset.tmpl"
+	ForcedPasswordResetTemplate = "forced_password_reset.tmpl"
+	maxRetries                  = 3
+)
+
+//go:embed templates
+var FS embed.FS
+
+type Client interface {
+	Send(templateFile, username, email string, data any) error
+}
+
+type message struct {
+	Subject string
+	Body    string
+}
+
+func render(templateFile string, data any) (*message, error) {
+	tmpl, err := template.ParseFS(FS, "templates/"+templateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	subject := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	body := new(bytes.Buffer)
+	if err := tmpl.ExecuteTemplate(body, "body", data); err != nil {
+		return nil, err
+	}
+
+	return &message{
+		Subject: subject.String(),
+		Body:    body.String(),
+	}, nil
+}
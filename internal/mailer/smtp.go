@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+type SMTPMailer struct {
+	addr      string
+	auth      smtp.Auth
+	fromEmail string
+}
+
+func NewSMTP(host string, port int, username, password, fromEmail string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr:      fmt.Sprintf("%s:%d", host, port),
+		auth:      auth,
+		fromEmail: fromEmail,
+	}
+}
+
+func (m *SMTPMailer) Send(templateFile, username, email string, data any) error {
+	msg, err := render(templateFile, data)
+	if err != nil {
+		return err
+	}
+
+	raw := strings.Join([]string{
+		"From: " + m.fromEmail,
+		"To: " + email,
+		"Subject: " + msg.Subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+		"",
+		msg.Body,
+	}, "\r\n")
+
+	for i := 0; i < maxRetries; i++ {
+		err = smtp.SendMail(m.addr, m.auth, m.fromEmail, []string{email}, []byte(raw))
+		if err == nil {
+			return nil
+		}
+
+		log.Printf("failed to send email to %v, attempt %d of %d: %v", email, i+1, maxRetries, err)
+		// back off before retrying
+		time.Sleep(time.Second * time.Duration(i+1))
+	}
+
+	return fmt.Errorf("failed to send email after %d attempts, error: %v", maxRetries, err)
+}
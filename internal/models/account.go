@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+type DeleteAccountPayload struct {
+	Password string `json:"password" validate:"required,max=72"`
+	Client   `json:"-"`
+}
+
+func (u *DeleteAccountPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type DeleteAccountResponse struct {
+	RestoreBefore time.Time `json:"restore_before"`
+}
+
+type RestoreAccountPayload struct {
+	Email    string `json:"email" validate:"required,email,max=72"`
+	Password string `json:"password" validate:"required,max=72"`
+	Client   `json:"-"`
+}
+
+func (u *RestoreAccountPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type AccountExport struct {
+	ExportedAt time.Time          `json:"exported_at"`
+	Profile    ExportProfile      `json:"profile"`
+	Posts      []ExportPost       `json:"posts"`
+	Comments   []ExportComment    `json:"comments"`
+	Reactions  []ExportReaction   `json:"reactions"`
+	Following  []ExportFollow     `json:"following"`
+	Followers  []ExportFollow     `json:"followers"`
+	Identities []IdentityResponse `json:"identities"`
+}
+
+type ExportProfile struct {
+	ID           int64  `json:"id"`
+	Username     string `json:"username"`
+	Fullname     string `json:"fullname"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	ImageProfile string `json:"image_profile"`
+	CreatedAt    string `json:"created_at"`
+	UpdatedAt    string `json:"updated_at"`
+}
+
+type ExportPost struct {
+	ID        int64           `json:"id"`
+	Title     string          `json:"title"`
+	Content   string          `json:"content"`
+	Tags      []string        `json:"tags"`
+	Images    []ImageResponse `json:"images"`
+	IsEdited  bool            `json:"is_edited"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
+}
+
+type ExportComment struct {
+	ID        int64  `json:"id"`
+	PostID    int64  `json:"post_id"`
+	Content   string `json:"content"`
+	IsEdited  bool   `json:"is_edited"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ExportReaction struct {
+	PostID    int64  `json:"post_id"`
+	CommentID *int64 `json:"comment_id,omitempty"`
+	Reaction  string `json:"reaction"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type ExportFollow struct {
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"created_at"`
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type LoginAttemptResponse struct {
+	ID        int64  `json:"id"`
+	UserID    *int64 `json:"user_id"`
+	Email     string `json:"email"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	Result    string `json:"result"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AdminUserResponse struct {
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Fullname  string     `json:"fullname"`
+	Email     string     `json:"email"`
+	Role      string     `json:"role"`
+	IsActive  bool       `json:"is_active"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	ImageURL  string     `json:"image_url"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+}
+
+type ChangeRolePayload struct {
+	Role   string `json:"role" validate:"required,max=255"`
+	Client `json:"-"`
+}
+
+func (u *ChangeRolePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type RoleResponse struct {
+	ID          int64    `json:"id"`
+	Name        string   `json:"name"`
+	Level       int64    `json:"level"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+}
+
+type PermissionResponse struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type AuditEventResponse struct {
+	ID         int64           `json:"id"`
+	ActorID    *int64          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int64           `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IPAddress  string          `json:"ip_address"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// AuditLogResponse is a page of the audit log, pass NextCursor as cursor to
+// get the next one. It is nil on the last page.
+type AuditLogResponse struct {
+	Events     []AuditEventResponse `json:"events"`
+	NextCursor *int64               `json:"next_cursor"`
+}
+
+// ForcedResetResponse tells whether the reset email went out, when it
+// didn't the reset still happened and forcing it again sends a new link.
+type ForcedResetResponse struct {
+	EmailSent bool `json:"email_sent"`
+}
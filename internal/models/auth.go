@@ -0,0 +1,138 @@
+package models
+
+import "time"
+
+// Client is the device a request came from, filled in by the handler.
+type Client struct {
+	IPAddress string
+	UserAgent string
+	RequestID string
+}
+
+type RefreshPayload struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (u *RefreshPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+type ChangePasswordPayload struct {
+	CurrentPassword string `json:"current_password" validate:"required,max=72"`
+	NewPassword     string `json:"new_password" validate:"required,min=5,max=72,nefield=CurrentPassword"`
+}
+
+func (u *ChangePasswordPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type ForgotPasswordPayload struct {
+	Email string `json:"email" validate:"required,email,max=72"`
+}
+
+func (u *ForgotPasswordPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type ResetPasswordPayload struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=5,max=72"`
+}
+
+func (u *ResetPasswordPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type LoginResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	*TokenResponse
+}
+
+type MFAVerifyPayload struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+	Client   `json:"-"`
+}
+
+func (u *MFAVerifyPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type MFACodePayload struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+func (u *MFACodePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+// TOTPEnrollResponse is what the client needs to add the account to an
+// authenticator app, OtpauthURI is also the text to render as a QR code.
+type TOTPEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauth_uri"`
+}
+
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// OIDCCallbackPayload carries BrowserState, the state from the cookie of the
+// browser that started the flow, next to the one the provider sent back.
+type OIDCCallbackPayload struct {
+	Code         string `json:"code" validate:"required"`
+	State        string `json:"state" validate:"required"`
+	BrowserState string `json:"-"`
+	Client       `json:"-"`
+}
+
+func (u *OIDCCallbackPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type OIDCAuthorizationResponse struct {
+	AuthorizationURL string `json:"authorization_url"`
+	State            string `json:"-"`
+}
+
+// OIDCCallbackResponse either logs in or, for a link flow, returns the
+// LinkToken the logged in user confirms the link with.
+type OIDCCallbackResponse struct {
+	Linked    bool   `json:"linked"`
+	LinkToken string `json:"link_token,omitempty"`
+	*LoginResponse
+}
+
+type OIDCLinkPayload struct {
+	LinkToken string `json:"link_token" validate:"required"`
+}
+
+func (u *OIDCLinkPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type IdentityResponse struct {
+	ID        int64  `json:"id"`
+	Provider  string `json:"provider"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type SessionResponse struct {
+	ID         int64     `json:"id"`
+	Device     string    `json:"device"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	Current    bool      `json:"current"`
+	CreatedAt  string    `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+type DraftResponse struct {
+	ID         int64      `json:"id"`
+	Title      string     `json:"title"`
+	Content    string     `json:"content"`
+	Tags       []string   `json:"tags"`
+	Images     []string   `json:"images"`
+	Status     string     `json:"status"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Visibility string     `json:"visibility"`
+	CreatedAt  string     `json:"created_at"`
+	UpdatedAt  string     `json:"updated_at"`
+}
+
+// DraftUpdatePayload only changes the fields that are set. Setting status to
+// published publishes the draft right away.
+type DraftUpdatePayload struct {
+	Title      *string    `json:"title" validate:"omitempty,min=5,max=255"`
+	Content    *string    `json:"content" validate:"omitempty,min=10"`
+	Tags       *[]string  `json:"tags"`
+	Status     *string    `json:"status" validate:"omitempty,oneof=draft scheduled published"`
+	PublishAt  *time.Time `json:"publish_at"`
+	Visibility *string    `json:"visibility" validate:"omitempty,oneof=public followers mentioned private"`
+}
+
+func (u *DraftUpdatePayload) Validate() error {
+	return Validate.Struct(u)
+}
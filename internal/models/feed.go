@@ -13,37 +13,63 @@ type PostsResponse struct {
 	MetaData MetaData        `json:"meta_data"`
 }
 
+// MetaData counts the comments and reactions of a post. Reactions is keyed by
+// kind and leaves out the kinds nobody used, LikeCount and DislikeCount
+// repeat two of them for older clients.
 type MetaData struct {
-	CommentCount int64 `json:"comments_count"`
-	LikeCount    int64 `json:"dlike_count"`
-	DislikeCount int64 `json:"dislike_count"`
+	CommentCount int64            `json:"comments_count"`
+	LikeCount    int64            `json:"dlike_count"`
+	DislikeCount int64            `json:"dislike_count"`
+	Reactions    map[string]int64 `json:"reactions"`
 }
 type PostResponse struct {
-	ID        int64             `json:"id"`
-	Title     string            `json:"title"`
-	Content   string            `json:"content"`
-	Tags      []string          `json:"tags"`
-	Images    []ImageResponse   `json:"images"`
-	IsEdited  bool              `json:"is_edited"`
-	CreatedAt string            `json:"created_at"`
-	UpdatedAt string            `json:"updated_at"`
-	Comments  []CommentResponse `json:"comments"`
-	User      UserFeedResponse  `json:"user"`
-	MetaData  MetaData          `json:"meta_data"`
+	ID         int64             `json:"id"`
+	Title      string            `json:"title"`
+	Content    string            `json:"content"`
+	Tags       []string          `json:"tags"`
+	Images     []ImageResponse   `json:"images"`
+	IsEdited   bool              `json:"is_edited"`
+	Visibility string            `json:"visibility"`
+	CreatedAt  string            `json:"created_at"`
+	UpdatedAt  string            `json:"updated_at"`
+	Comments   []CommentResponse `json:"comments"`
+	User       UserFeedResponse  `json:"user"`
+	MetaData   MetaData          `json:"meta_data"`
 }
 
 type ImageResponse struct {
+	ID        int64  `json:"id"`
 	ImageUrl  string `json:"image_url"`
 	ImageName string `json:"image_name"`
+	Position  int    `json:"position"`
+	AltText   string `json:"alt_text"`
 }
 
+// CommentResponse counts reactions like MetaData does, LikeCount and
+// DislikeCount repeat two kinds of Reactions.
 type CommentResponse struct {
-	UserID    int64  `json:"user_id"`
-	Username  string `json:"username"`
-	Content   string `json:"content"`
-	IsEdited  bool   `json:"is_edited"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID           int64             `json:"id"`
+	ParentID     *int64            `json:"parent_id"`
+	Depth        int               `json:"depth"`
+	UserID       int64             `json:"user_id"`
+	Username     string            `json:"username"`
+	ImageURL     string            `json:"image_url"`
+	Content      string            `json:"content"`
+	Status       string            `json:"status,omitempty"`
+	IsEdited     bool              `json:"is_edited"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+	ReplyCount   int64             `json:"reply_count"`
+	LikeCount    int64             `json:"like_count"`
+	DislikeCount int64             `json:"dislike_count"`
+	Reactions    map[string]int64  `json:"reactions"`
+	Replies      []CommentResponse `json:"replies,omitempty"`
+}
+
+// CommentsResponse is a page of comments, each with its first replies.
+type CommentsResponse struct {
+	Comments   []CommentResponse `json:"comments"`
+	NextCursor *string           `json:"next_cursor"`
 }
 
 type UserFeedResponse struct {
@@ -52,8 +78,15 @@ type UserFeedResponse struct {
 }
 
 type UserActivitiesPayload struct {
-	UserID int64 `json:"user_id"`
-	PostID int64 `json:"post_id"`
+	UserID    int64  `json:"user_id"`
+	PostID    int64  `json:"post_id"`
+	CommentID int64  `json:"comment_id"`
+	Reaction  string `json:"reaction"`
+}
+
+type ReactionResponse struct {
+	Kind  string `json:"kind"`
+	Emoji string `json:"emoji"`
 }
 
 type CommentPayload struct {
@@ -65,3 +98,19 @@ type CommentPayload struct {
 func (u *CommentPayload) Validate() error {
 	return Validate.Struct(u)
 }
+
+type CommentUpdatePayload struct {
+	Content string `json:"content" validate:"required,max=255"`
+}
+
+func (u *CommentUpdatePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type CommentRevisionResponse struct {
+	ID               int64  `json:"id"`
+	Content          string `json:"content"`
+	EditedBy         *int64 `json:"edited_by"`
+	VersionCreatedAt string `json:"version_created_at"`
+	ReplacedAt       string `json:"replaced_at"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "mime/multipart"
+import (
+	"mime/multipart"
+	"time"
+)
 
 type PostPayload struct {
 	UserID  int64                   `json:"user_id" form:"user_id"`
@@ -8,6 +11,12 @@ type PostPayload struct {
 	Content string                  `json:"content" form:"content" validate:"required,min=10"`
 	Tags    []string                `json:"tags" form:"tags" validate:"omitempty"`
 	Images  []*multipart.FileHeader `json:"images" form:"images" validate:"omitempty"`
+	// AltTexts pairs with Images by index
+	AltTexts []string `json:"alt_texts" form:"alt_text" validate:"omitempty,dive,max=500"`
+	// Status defaults to published, scheduled posts need PublishAt
+	Status     string     `json:"status" form:"status" validate:"omitempty,oneof=draft scheduled published"`
+	Visibility string     `json:"visibility" form:"visibility" validate:"omitempty,oneof=public followers mentioned private"`
+	PublishAt  *time.Time `json:"publish_at" form:"publish_at"`
 }
 
 func (u *PostPayload) Validate() error {
@@ -20,15 +29,45 @@ type ImagePayload struct {
 }
 
 type PostUpdatePayload struct {
-	Title   *string   `json:"title" form:"title" validate:"omitempty,min=5,max=255"`
-	Content *string   `json:"content" form:"content" validate:"omitempty,min=10"`
-	Tags    *[]string `json:"tags" form:"tags"`
+	Title      *string   `json:"title" form:"title" validate:"omitempty,min=5,max=255"`
+	Content    *string   `json:"content" form:"content" validate:"omitempty,min=10"`
+	Tags       *[]string `json:"tags" form:"tags"`
+	Visibility *string   `json:"visibility" form:"visibility" validate:"omitempty,oneof=public followers mentioned private"`
+	Client     `json:"-"`
 }
 
 func (u *PostUpdatePayload) Validate() error {
 	return Validate.Struct(u)
 }
 
+type ImagesPayload struct {
+	Images   []*multipart.FileHeader `json:"images" form:"images" validate:"required,min=1"`
+	AltTexts []string                `json:"alt_texts" form:"alt_text" validate:"omitempty,dive,max=500"`
+	Client   `json:"-"`
+}
+
+func (u *ImagesPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type ImageOrderPayload struct {
+	ImageIDs []int64 `json:"image_ids" validate:"required,min=1"`
+	Client   `json:"-"`
+}
+
+func (u *ImageOrderPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+type ImageUpdatePayload struct {
+	AltText string `json:"alt_text" validate:"max=500"`
+	Client  `json:"-"`
+}
+
+func (u *ImageUpdatePayload) Validate() error {
+	return Validate.Struct(u)
+}
+
 type PostsByUserResponse struct {
 	ID       int64    `json:"id"`
 	Title    string   `json:"title"`
@@ -0,0 +1,30 @@
+package models
+
+type RevisionResponse struct {
+	Revision         int      `json:"revision"`
+	Title            string   `json:"title"`
+	Content          string   `json:"content"`
+	Tags             []string `json:"tags"`
+	EditedBy         *int64   `json:"edited_by"`
+	EditedByUsername string   `json:"edited_by_username"`
+	VersionCreatedAt string   `json:"version_created_at"`
+	ReplacedAt       string   `json:"replaced_at"`
+}
+
+// DiffLine is one line of a line based diff, Op is equal, insert or delete.
+type DiffLine struct {
+	Op   string `json:"op"`
+	Text string `json:"text"`
+}
+
+// RevisionDiffResponse compares a revision with the version that replaced
+// it, NextRevision is nil when that is the current post.
+type RevisionDiffResponse struct {
+	Revision     RevisionResponse `json:"revision"`
+	NextRevision *int             `json:"next_revision"`
+	TitleFrom    string           `json:"title_from"`
+	TitleTo      string           `json:"title_to"`
+	TagsAdded    []string         `json:"tags_added"`
+	TagsRemoved  []string         `json:"tags_removed"`
+	Content      []DiffLine       `json:"content"`
+}
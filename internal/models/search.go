@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+type SearchPayload struct {
+	Query  string `json:"q" validate:"required,max=200"`
+	Type   string `json:"type" validate:"oneof=posts users tags"`
+	Limit  int    `json:"limit" validate:"gte=1,lte=50"`
+	Cursor string `json:"cursor"`
+}
+
+func (u *SearchPayload) Validate() error {
+	return Validate.Struct(u)
+}
+
+// SearchResponse holds the results of the searched type, a list of
+// SearchPostResponse, SearchUserResponse or SearchTagResponse. The text is
+// HTML escaped with the matches wrapped in <mark> tags. NextCursor is nil on
+// the last page.
+type SearchResponse struct {
+	Type       string  `json:"type"`
+	Results    any     `json:"results"`
+	NextCursor *string `json:"next_cursor"`
+}
+
+type SearchPostResponse struct {
+	ID          int64      `json:"id"`
+	UserID      int64      `json:"user_id"`
+	Username    string     `json:"username"`
+	Title       string     `json:"title"`
+	Snippet     string     `json:"snippet"`
+	Tags        []string   `json:"tags"`
+	PublishedAt *time.Time `json:"published_at"`
+	Rank        float32    `json:"rank"`
+}
+
+type SearchUserResponse struct {
+	ID       int64   `json:"id"`
+	Username string  `json:"username"`
+	Fullname string  `json:"fullname"`
+	ImageURL string  `json:"image_url"`
+	Rank     float32 `json:"rank"`
+}
+
+type SearchTagResponse struct {
+	Name  string  `json:"name"`
+	Posts int64   `json:"posts"`
+	Rank  float32 `json:"rank"`
+}
@@ -0,0 +1,12 @@
+package models
+
+type TagPostsResponse struct {
+	Tag   string          `json:"tag"`
+	Posts []PostsResponse `json:"posts"`
+}
+
+type TrendingTagResponse struct {
+	Name  string  `json:"name"`
+	Uses  int64   `json:"uses"`
+	Score float64 `json:"score"`
+}
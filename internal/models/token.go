@@ -0,0 +1,60 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+)
+
+type PersonalTokenPayload struct {
+	Name          string   `json:"name" validate:"required,min=3,max=100"`
+	Scopes        []string `json:"scopes" validate:"required,min=1"`
+	ExpiresInDays *int     `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+func (u *PersonalTokenPayload) Validate() error {
+	if err := Validate.Struct(u); err != nil {
+		return err
+	}
+
+	return validateScopes(u.Scopes)
+}
+
+type PersonalTokenUpdatePayload struct {
+	Name   *string   `json:"name" validate:"omitempty,min=3,max=100"`
+	Scopes *[]string `json:"scopes" validate:"omitempty,min=1"`
+}
+
+func (u *PersonalTokenUpdatePayload) Validate() error {
+	if err := Validate.Struct(u); err != nil {
+		return err
+	}
+
+	if u.Scopes != nil {
+		return validateScopes(*u.Scopes)
+	}
+
+	return nil
+}
+
+func validateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if !auth.IsValidScope(scope) {
+			return fmt.Errorf("unknown scope %q, allowed scopes: %v", scope, auth.Scopes)
+		}
+	}
+
+	return nil
+}
+
+type PersonalTokenResponse struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Token      string     `json:"token,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  string     `json:"created_at"`
+	UpdatedAt  string     `json:"updated_at"`
+}
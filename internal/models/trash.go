@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type TrashPostResponse struct {
+	ID         int64     `json:"id"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	Tags       []string  `json:"tags"`
+	Images     []string  `json:"images"`
+	IsEdited   bool      `json:"is_edited"`
+	CreatedAt  string    `json:"created_at"`
+	DeletedAt  time.Time `json:"deleted_at"`
+	PurgeAfter time.Time `json:"purge_after"`
+}
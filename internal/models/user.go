@@ -27,6 +27,7 @@ func (u *UserPayload) Validate() error {
 type LoginPayload struct {
 	Email    string `json:"email" validate:"required,email,max=72"`
 	Password string `json:"password" validate:"required,min=5,max=72"`
+	Client   `json:"-"`
 }
 
 func (u *LoginPayload) Validate() error {
@@ -0,0 +1,208 @@
+package rbac
+
+import (
+	"cmp"
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/lib/pq"
+)
+
+// ChangeChannel is notified by the database triggers whenever roles,
+// permissions or grants change.
+const ChangeChannel = "rbac_changed"
+
+type Store interface {
+	GetAll(context.Context) ([]postgresql.Role, error)
+}
+
+type role struct {
+	postgresql.Role
+	permissions map[string]bool
+}
+
+// Cache keeps roles and their permissions in memory so authorization checks
+// don't hit the database. It is reloaded when the database reports a change
+// and on a fixed interval in case a notification was missed.
+type Cache struct {
+	store Store
+
+	mu     sync.RWMutex
+	roles  map[string]*role
+	loaded bool
+}
+
+func NewCache(store Store) *Cache {
+	return &Cache{
+		store: store,
+		roles: map[string]*role{},
+	}
+}
+
+func (c *Cache) Load(ctx context.Context) error {
+	roles, err := c.store.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+
+	loaded := make(map[string]*role, len(roles))
+	for _, r := range roles {
+		perms := make(map[string]bool, len(r.Permissions))
+		for _, p := range r.Permissions {
+			perms[p] = true
+		}
+		loaded[r.Name] = &role{Role: r, permissions: perms}
+	}
+
+	c.mu.Lock()
+	c.roles = loaded
+	c.loaded = true
+	c.mu.Unlock()
+
+	return nil
+}
+
+func (c *Cache) ensureLoaded(ctx context.Context) error {
+	c.mu.RLock()
+	loaded := c.loaded
+	c.mu.RUnlock()
+
+	if loaded {
+		return nil
+	}
+
+	return c.Load(ctx)
+}
+
+// HasPermission reports whether the role is granted the permission, unknown
+// roles have none.
+func (c *Cache) HasPermission(ctx context.Context, roleName, permission string) (bool, error) {
+	if err := c.ensureLoaded(ctx); err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	r, ok := c.roles[roleName]
+	if !ok {
+		return false, nil
+	}
+
+	return r.permissions[permission], nil
+}
+
+// Covers reports whether the role has every permission of the other role,
+// unknown roles have none.
+func (c *Cache) Covers(ctx context.Context, roleName, otherRole string) (bool, error) {
+	if err := c.ensureLoaded(ctx); err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.covers(roleName, otherRole), nil
+}
+
+// Outranks reports whether the role covers the other role and has at least
+// one permission more, a role never outranks itself.
+func (c *Cache) Outranks(ctx context.Context, roleName, otherRole string) (bool, error) {
+	if err := c.ensureLoaded(ctx); err != nil {
+		return false, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.covers(roleName, otherRole) && !c.covers(otherRole, roleName), nil
+}
+
+func (c *Cache) covers(roleName, otherRole string) bool {
+	r := c.roles[roleName]
+	other, ok := c.roles[otherRole]
+	if !ok {
+		return true
+	}
+
+	for p := range other.permissions {
+		if r == nil || !r.permissions[p] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (c *Cache) GetRole(ctx context.Context, roleName string) (*postgresql.Role, error) {
+	if err := c.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	r, ok := c.roles[roleName]
+	if !ok {
+		return nil, postgresql.ErrNotFound
+	}
+
+	role := r.Role
+	return &role, nil
+}
+
+func (c *Cache) GetRoles(ctx context.Context) ([]postgresql.Role, error) {
+	if err := c.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	roles := make([]postgresql.Role, 0, len(c.roles))
+	for _, r := range c.roles {
+		roles = append(roles, r.Role)
+	}
+
+	slices.SortFunc(roles, func(a, b postgresql.Role) int {
+		return cmp.Compare(a.Level, b.Level)
+	})
+
+	return roles, nil
+}
+
+// Listen reloads the cache on every change notification and every refresh
+// interval until the context is done. A nil notification means the listener
+// reconnected and may have missed some, so it reloads then too.
+func (c *Cache) Listen(ctx context.Context, dsn string, refresh time.Duration) {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("rbac listener: %v", err)
+		}
+	})
+	defer listener.Close()
+
+	if err := listener.Listen(ChangeChannel); err != nil {
+		log.Printf("failed to listen for rbac changes, falling back to refresh every %s: %v", refresh, err)
+	}
+
+	ticker := time.NewTicker(refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-listener.Notify:
+		case <-ticker.C:
+		}
+
+		if err := c.Load(ctx); err != nil {
+			log.Printf("failed to reload roles and permissions: %v", err)
+		}
+	}
+}
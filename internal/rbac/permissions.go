@@ -0,0 +1,18 @@
+package rbac
+
+// permissions seeded by the migrations, roles are granted them through the
+// role_permissions table.
+const (
+	PostUpdateAny     = "post.update.any"
+	PostDeleteAny     = "post.delete.any"
+	PostReadAny       = "post.read.any"
+	PostRestoreAny    = "post.restore.any"
+	CommentDeleteAny  = "comment.delete.any"
+	UserReadAny       = "user.read.any"
+	UserBan           = "user.ban"
+	UserRoleUpdate    = "user.role.update"
+	UserPasswordReset = "user.password.reset"
+	LoginAttemptRead  = "login_attempt.read"
+	RoleManage        = "role.manage"
+	AuditRead         = "audit.read"
+)
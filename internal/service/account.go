@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/cldnary"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const purgeBatchSize = 50
+
+type AccountService struct {
+	storage    *postgresql.Storage
+	cloudinary cldnary.ClientCloudinary
+}
+
+// deletionGracePeriod is how long a deleted account can still be restored.
+func deletionGracePeriod() time.Duration {
+	return time.Hour * 24 * time.Duration(env.GetInt("ACCOUNT_DELETION_GRACE_DAYS", 30))
+}
+
+func (s *AccountService) DeleteAccount(ctx context.Context, user *postgresql.User, payload *models.DeleteAccountPayload) (*models.DeleteAccountResponse, error) {
+	if err := user.Password.Compared(payload.Password); err != nil {
+		return nil, ErrInvalidPassword
+	}
+
+	event, err := newAuditEvent(user, AuditAccountDelete, postgresql.AuditTargetUser, user.ID, nil, nil, payload.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	deletedAt, err := s.storage.Accounts.Deactivate(ctx, user.ID, event)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DeleteAccountResponse{
+		RestoreBefore: deletedAt.Add(deletionGracePeriod()),
+	}, nil
+}
+
+// RestoreAccount brings a deleted account back inside the grace period and
+// logs the user in. It goes through the same brute force protection as login.
+func (s *AuthService) RestoreAccount(ctx context.Context, payload *models.RestoreAccountPayload) (*models.LoginResponse, error) {
+	attempt := &postgresql.LoginAttempt{
+		Email:     strings.ToLower(payload.Email),
+		IPAddress: payload.IPAddress,
+		UserAgent: truncate(payload.UserAgent, maxUserAgentLength),
+	}
+
+	wait, err := s.loginBackoff(ctx, attempt.Email, attempt.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginLocked)
+		return nil, fmt.Errorf("%w, try again in %s", ErrTooManyAttempts, wait.Round(time.Second))
+	}
+
+	deleted, err := s.storage.Accounts.GetDeletedByEmail(ctx, payload.Email)
+	if err != nil {
+		if !errors.Is(err, postgresql.ErrNotFound) {
+			return nil, err
+		}
+
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(payload.Password))
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginFailed)
+		return nil, ErrInvalidCredentials
+	}
+
+	attempt.UserID = &deleted.ID
+	if err := deleted.Password.Compared(payload.Password); err != nil {
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginFailed)
+		return nil, ErrInvalidCredentials
+	}
+
+	// past the grace period the account is as good as gone
+	if err := s.storage.Accounts.Restore(ctx, deleted.ID, time.Now().Add(-deletionGracePeriod())); err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			s.recordLoginAttempt(ctx, attempt, postgresql.LoginFailed)
+			return nil, ErrInvalidCredentials
+		}
+		return nil, err
+	}
+
+	user, err := s.storage.Users.GetByID(ctx, deleted.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.completePasswordLogin(ctx, attempt, user, payload.Client)
+}
+
+func (s *AccountService) ExportAccount(ctx context.Context, user *postgresql.User) (*models.AccountExport, error) {
+	data, err := s.storage.Accounts.Export(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	identities, err := s.storage.Identities.GetByUser(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.AccountExport{
+		ExportedAt: time.Now().UTC(),
+		Profile: models.ExportProfile{
+			ID:           user.ID,
+			Username:     user.Username,
+			Fullname:     user.Fullname,
+			Email:        user.Email,
+			Role:         user.Role.Name,
+			ImageProfile: user.ImgURL.ImageURL,
+			CreatedAt:    user.CreatedAt,
+			UpdatedAt:    user.UpdatedAt,
+		},
+		Posts:      []models.ExportPost{},
+		Comments:   []models.ExportComment{},
+		Reactions:  []models.ExportReaction{},
+		Following:  []models.ExportFollow{},
+		Followers:  []models.ExportFollow{},
+		Identities: []models.IdentityResponse{},
+	}
+
+	for _, p := range data.Posts {
+		post := models.ExportPost{
+			ID:        p.ID,
+			Title:     p.Title,
+			Content:   p.Content,
+			Tags:      p.Tags,
+			Images:    []models.ImageResponse{},
+			IsEdited:  p.IsEdited,
+			CreatedAt: p.CreatedAt,
+			UpdatedAt: p.UpdatedAt,
+		}
+		for _, img := range p.Images {
+			post.Images = append(post.Images, models.ImageResponse{
+				ImageUrl:  img.ImageURL,
+				ImageName: img.ImageName,
+			})
+		}
+		export.Posts = append(export.Posts, post)
+	}
+
+	for _, c := range data.Comments {
+		export.Comments = append(export.Comments, models.ExportComment{
+			ID:        c.ID,
+			PostID:    c.PostID,
+			Content:   c.Content,
+			IsEdited:  c.IsEdited,
+			CreatedAt: c.CreatedAt,
+			UpdatedAt: c.UpdatedAt,
+		})
+	}
+
+	for _, a := range data.Reactions {
+		export.Reactions = append(export.Reactions, models.ExportReaction{
+			PostID:    a.PostID,
+			CommentID: a.CommentID,
+			Reaction:  a.Reaction,
+			CreatedAt: a.CreatedAt,
+			UpdatedAt: a.UpdatedAt,
+		})
+	}
+
+	for _, f := range data.Following {
+		export.Following = append(export.Following, models.ExportFollow(f))
+	}
+
+	for _, f := range data.Followers {
+		export.Followers = append(export.Followers, models.ExportFollow(f))
+	}
+
+	for _, i := range identities {
+		export.Identities = append(export.Identities, models.IdentityResponse{
+			ID:        i.ID,
+			Provider:  i.Provider,
+			Email:     i.Email,
+			CreatedAt: i.CreatedAt,
+		})
+	}
+
+	return export, nil
+}
+
+// PurgeDeletedAccounts hard deletes accounts whose grace period is over and
+// removes their uploaded images, it returns how many accounts were purged.
+func (s *AccountService) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		purged, err := s.storage.Accounts.PurgeDeleted(ctx, time.Now().Add(-deletionGracePeriod()), purgeBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += len(purged)
+
+		for _, user := range purged {
+			images := user.PostImages
+			// the default avatars are shared, never delete them
+			if user.ProfileImage != "" && !slices.Contains(defaultImage, user.ProfileImage) {
+				images = append(images, user.ProfileImage)
+			}
+			deleteRemoteImages(ctx, s.cloudinary, images)
+		}
+
+		if len(purged) < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
+
+// deleteRemoteImages is best effort, the rows are already gone so a failure
+// only leaves an orphaned file behind.
+func deleteRemoteImages(ctx context.Context, cloudinary cldnary.ClientCloudinary, urls []string) {
+	for _, url := range urls {
+		publicID, ok := cldnary.PublicIDFromURL(url)
+		if !ok {
+			continue
+		}
+
+		if err := cloudinary.DeleteImage(ctx, publicID); err != nil {
+			log.Printf("failed to delete image %s: %v", publicID, err)
+		}
+	}
+}
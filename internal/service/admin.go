@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/mailer"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+// a forced reset is not expected by the user, give them a day to notice
+const forcedResetExp = time.Hour * 24
+
+var (
+	ErrSelfAction  = errors.New("admins can't change their own role, status or password this way")
+	ErrUnknownRole = errors.New("role does not exist")
+	ErrOutranked   = errors.New("the role has permissions you don't have")
+)
+
+type AdminService struct {
+	storage *postgresql.Storage
+	mailer  mailer.Client
+	rbac    *rbac.Cache
+}
+
+// checkOutranks lets the actor manage only users whose role has fewer
+// permissions than their own, so peers can't demote, ban or lock out each
+// other and nobody climbs above their own permissions.
+func (s *AdminService) checkOutranks(ctx context.Context, actor, target *postgresql.User) error {
+	outranks, err := s.rbac.Outranks(ctx, actor.Role.Name, target.Role.Name)
+	if err != nil {
+		return err
+	}
+
+	if !outranks {
+		return ErrOutranked
+	}
+
+	return nil
+}
+
+func (s *AdminService) GetLoginAttempts(ctx context.Context, filter postgresql.LoginAttemptFilter) ([]models.LoginAttemptResponse, error) {
+	attempts, err := s.storage.LoginAttempts.GetAttempts(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []models.LoginAttemptResponse{}
+	for _, a := range attempts {
+		resp = append(resp, models.LoginAttemptResponse{
+			ID:        a.ID,
+			UserID:    a.UserID,
+			Email:     a.Email,
+			IPAddress: a.IPAddress,
+			UserAgent: a.UserAgent,
+			Result:    a.Result,
+			CreatedAt: a.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) GetAuditEvents(ctx context.Context, filter postgresql.AuditFilter) (*models.AuditLogResponse, error) {
+	events, err := s.storage.Audit.GetEvents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.AuditLogResponse{
+		Events: []models.AuditEventResponse{},
+	}
+	for _, e := range events {
+		resp.Events = append(resp.Events, models.AuditEventResponse{
+			ID:         e.ID,
+			ActorID:    e.ActorID,
+			Action:     e.Action,
+			TargetType: e.TargetType,
+			TargetID:   e.TargetID,
+			Before:     e.Before,
+			After:      e.After,
+			IPAddress:  e.IPAddress,
+			RequestID:  e.RequestID,
+			CreatedAt:  e.CreatedAt,
+		})
+	}
+
+	// a full page may have more behind it
+	if len(events) == filter.Limit {
+		next := events[len(events)-1].ID
+		resp.NextCursor = &next
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) GetUsers(ctx context.Context, filter postgresql.UserFilter) ([]models.AdminUserResponse, error) {
+	users, err := s.storage.Admin.GetUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []models.AdminUserResponse{}
+	for _, u := range users {
+		resp = append(resp, toAdminUserResponse(&u))
+	}
+
+	return resp, nil
+}
+
+func (s *AdminService) GetUser(ctx context.Context, userID int64) (*models.AdminUserResponse, error) {
+	user, err := s.storage.Admin.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toAdminUserResponse(user)
+	return &resp, nil
+}
+
+func (s *AdminService) ChangeRole(ctx context.Context, actor *postgresql.User, userID int64, payload *models.ChangeRolePayload) (*models.AdminUserResponse, error) {
+	if actor.ID == userID {
+		return nil, ErrSelfAction
+	}
+
+	user, err := s.storage.Admin.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.storage.Roles.GetByName(ctx, payload.Role); err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return nil, ErrUnknownRole
+		}
+		return nil, err
+	}
+
+	if err := s.checkOutranks(ctx, actor, user); err != nil {
+		return nil, err
+	}
+
+	// a role can be handed out up to the actor's own, never above it
+	covers, err := s.rbac.Covers(ctx, actor.Role.Name, payload.Role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !covers {
+		return nil, ErrOutranked
+	}
+
+	if user.Role.Name == payload.Role {
+		resp := toAdminUserResponse(user)
+		return &resp, nil
+	}
+
+	event, err := newAuditEvent(actor, AuditUserRoleUpdate, postgresql.AuditTargetUser, user.ID,
+		map[string]string{"role": user.Role.Name},
+		map[string]string{"role": payload.Role},
+		payload.Client,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Admin.UpdateRole(ctx, user.ID, payload.Role, event); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(ctx, user.ID)
+}
+
+// SetActive deactivates or reactivates an account, reactivating also cancels
+// a pending self-service deletion.
+func (s *AdminService) SetActive(ctx context.Context, actor *postgresql.User, userID int64, active bool, client models.Client) (*models.AdminUserResponse, error) {
+	if actor.ID == userID {
+		return nil, ErrSelfAction
+	}
+
+	user, err := s.storage.Admin.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOutranks(ctx, actor, user); err != nil {
+		return nil, err
+	}
+
+	action, deletedAt := AuditUserDeactivate, user.DeletedAt
+	if active {
+		action, deletedAt = AuditUserReactivate, nil
+	}
+
+	event, err := newAuditEvent(actor, action, postgresql.AuditTargetUser, user.ID,
+		map[string]any{"is_active": user.IsActive, "deleted_at": user.DeletedAt},
+		map[string]any{"is_active": active, "deleted_at": deletedAt},
+		client,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Admin.SetActive(ctx, user.ID, active, event); err != nil {
+		return nil, err
+	}
+
+	return s.GetUser(ctx, user.ID)
+}
+
+// ForcePasswordReset locks the user out until they pick a new password
+// through the emailed reset link. The reset is committed before the email is
+// sent, a failed email is reported rather than failing an action that
+// already happened.
+func (s *AdminService) ForcePasswordReset(ctx context.Context, actor *postgresql.User, userID int64, client models.Client) (*models.ForcedResetResponse, error) {
+	if actor.ID == userID {
+		return nil, ErrSelfAction
+	}
+
+	user, err := s.storage.Admin.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkOutranks(ctx, actor, user); err != nil {
+		return nil, err
+	}
+
+	// nobody knows this password, the reset link is the only way back in
+	unusable, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var password postgresql.Password
+	if err := password.Set(unusable); err != nil {
+		return nil, err
+	}
+
+	plainToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := newAuditEvent(actor, AuditUserPasswordForced, postgresql.AuditTargetUser, user.ID, nil, nil, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Passwords.ForcePasswordReset(ctx, user.ID, password.Hash, hash, forcedResetExp, event); err != nil {
+		return nil, err
+	}
+
+	data := struct {
+		Username  string
+		ResetURL  string
+		ExpiresIn string
+	}{
+		Username:  user.Username,
+		ResetURL:  fmt.Sprintf("%s/reset-password/%s", env.GetString("FRONTEND_URL", "http://localhost:3000"), plainToken),
+		ExpiresIn: forcedResetExp.String(),
+	}
+
+	if err := s.mailer.Send(mailer.ForcedPasswordResetTemplate, user.Username, user.Email, data); err != nil {
+		log.Printf("failed to send forced password reset to user %d: %v", user.ID, err)
+		return &models.ForcedResetResponse{EmailSent: false}, nil
+	}
+
+	return &models.ForcedResetResponse{EmailSent: true}, nil
+}
+
+func toAdminUserResponse(u *postgresql.User) models.AdminUserResponse {
+	return models.AdminUserResponse{
+		ID:        u.ID,
+		Username:  u.Username,
+		Fullname:  u.Fullname,
+		Email:     u.Email,
+		Role:      u.Role.Name,
+		IsActive:  u.IsActive,
+		DeletedAt: u.DeletedAt,
+		ImageURL:  u.ImgURL.ImageURL,
+		CreatedAt: u.CreatedAt,
+		UpdatedAt: u.UpdatedAt,
+	}
+}
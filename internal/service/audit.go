@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+const (
+	AuditUserRoleUpdate     = "user.role.update"
+	AuditUserDeactivate     = "user.deactivate"
+	AuditUserReactivate     = "user.reactivate"
+	AuditUserPasswordForced = "user.password.force_reset"
+
+	AuditAccountDelete = "account.delete"
+	AuditUserLogin     = "user.login"
+
+	AuditPostUpdate  = "post.update"
+	AuditPostDelete  = "post.delete"
+	AuditPostRestore = "post.revision.restore"
+
+	AuditPostImageAdd     = "post.image.add"
+	AuditPostImageRemove  = "post.image.remove"
+	AuditPostImageReorder = "post.image.reorder"
+	AuditPostImageUpdate  = "post.image.update"
+
+	AuditCommentDelete = "comment.delete"
+	AuditCommentHide   = "comment.hide"
+	AuditCommentUnhide = "comment.unhide"
+
+	AuditRolePermissionGrant  = "role.permission.grant"
+	AuditRolePermissionRevoke = "role.permission.revoke"
+)
+
+// newAuditEvent builds the record of a change, before and after are
+// marshalled as they are so callers pass only the fields that changed.
+func newAuditEvent(actor *postgresql.User, action, targetType string, targetID int64, before, after any, client models.Client) (*postgresql.AuditEvent, error) {
+	event := &postgresql.AuditEvent{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		IPAddress:  client.IPAddress,
+		RequestID:  client.RequestID,
+	}
+
+	if actor != nil {
+		event.ActorID = &actor.ID
+	}
+
+	var err error
+	if before != nil {
+		if event.Before, err = json.Marshal(before); err != nil {
+			return nil, err
+		}
+	}
+
+	if after != nil {
+		if event.After, err = json.Marshal(after); err != nil {
+			return nil, err
+		}
+	}
+
+	return event, nil
+}
+
+// recordLogin audits a session being handed out. Like login attempts it is
+// best effort, a failed write must not lock the user out.
+func (s *AuthService) recordLogin(ctx context.Context, session *postgresql.Session, client models.Client) {
+	event, err := newAuditEvent(nil, AuditUserLogin, postgresql.AuditTargetUser, session.UserID, nil,
+		map[string]any{"session_id": session.ID, "user_agent": session.UserAgent},
+		client,
+	)
+	if err != nil {
+		log.Printf("failed to build login event for user %d: %v", session.UserID, err)
+		return
+	}
+	event.ActorID = &session.UserID
+
+	if err := s.storage.Audit.CreateEvent(ctx, event); err != nil {
+		log.Printf("failed to record login of user %d: %v", session.UserID, err)
+	}
+}
@@ -2,18 +2,39 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log"
 	"math/rand/v2"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
 	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/mailer"
 	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/oidc"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/cldnary"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
 )
 
-const folderProfile = "Profiles"
+const (
+	folderProfile    = "Profiles"
+	accessTokenExp   = time.Minute * 15
+	refreshTokenExp  = time.Hour * 24 * 30
+	invitationExp    = time.Hour * 24 * 3
+	passwordResetExp = time.Minute * 30
+	mfaTokenExp      = time.Minute * 5
+	mfaTokenType     = "mfa"
+)
+
+var (
+	ErrInvalidToken    = errors.New("invalid or expired token")
+	ErrInvalidPassword = errors.New("current password is incorrect")
+)
 
 var defaultImage = []string{
 	"https://res.cloudinary.com/drbxy46kq/image/upload/v1736916378/Minimalist_Avatar_1_ayc9ei.jpg",
@@ -25,6 +46,8 @@ type AuthService struct {
 	storage    *postgresql.Storage
 	auth       auth.Authenticator
 	cloudinary cldnary.ClientCloudinary
+	mailer     mailer.Client
+	oidc       *oidc.Provider
 }
 
 func (s *AuthService) RegisterUser(ctx context.Context, payload *models.UserPayload) error {
@@ -45,26 +68,253 @@ func (s *AuthService) RegisterUser(ctx context.Context, payload *models.UserPayl
 		ImageURL: imgUrl,
 	}
 
-	if err := s.storage.Users.CreateUser(ctx, &user, &img); err != nil {
+	plainToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := s.storage.Users.CreateAndInvite(ctx, &user, &img, hash, invitationExp); err != nil {
+		return err
+	}
+
+	data := struct {
+		Username      string
+		ActivationURL string
+		ExpiresIn     string
+	}{
+		Username:      user.Username,
+		ActivationURL: fmt.Sprintf("%s/confirm/%s", env.GetString("FRONTEND_URL", "http://localhost:3000"), plainToken),
+		ExpiresIn:     invitationExp.String(),
+	}
+
+	if err := s.mailer.Send(mailer.UserInvitationTemplate, user.Username, user.Email, data); err != nil {
+		// rollback user creation if the invitation could not be delivered
+		if err := s.storage.Users.Delete(ctx, user.ID); err != nil {
+			log.Printf("failed to delete user %d after mail error: %v", user.ID, err)
+		}
 		return err
 	}
 
 	return nil
 }
 
-func (s *AuthService) LoginUser(ctx context.Context, payload *models.LoginPayload) (string, error) {
+func (s *AuthService) ActivateUser(ctx context.Context, token string) error {
+	return s.storage.Users.Activate(ctx, auth.HashToken(token))
+}
+
+func (s *AuthService) LoginUser(ctx context.Context, payload *models.LoginPayload) (*models.LoginResponse, error) {
+	attempt := &postgresql.LoginAttempt{
+		Email:     strings.ToLower(payload.Email),
+		IPAddress: payload.IPAddress,
+		UserAgent: truncate(payload.UserAgent, maxUserAgentLength),
+	}
+
+	wait, err := s.loginBackoff(ctx, attempt.Email, attempt.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginLocked)
+		return nil, fmt.Errorf("%w, try again in %s", ErrTooManyAttempts, wait.Round(time.Second))
+	}
+
 	user, err := s.storage.Users.GetByEmail(ctx, payload.Email)
 	if err != nil {
-		return "", err
+		if !errors.Is(err, postgresql.ErrNotFound) {
+			return nil, err
+		}
+
+		// spend the same time as a wrong password so unknown emails can't be told apart
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(payload.Password))
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginFailed)
+		return nil, ErrInvalidCredentials
 	}
 
+	attempt.UserID = &user.ID
 	if err := user.Password.Compared(payload.Password); err != nil {
-		return "", err
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginFailed)
+		return nil, ErrInvalidCredentials
+	}
+
+	return s.completePasswordLogin(ctx, attempt, user, payload.Client)
+}
+
+// completePasswordLogin records the attempt as a success unless a second
+// factor is still due. A success resets the failure count of the account, so
+// it has to wait for the code or every password login would give the second
+// factor a fresh set of guesses.
+func (s *AuthService) completePasswordLogin(ctx context.Context, attempt *postgresql.LoginAttempt, user *postgresql.User, client models.Client) (*models.LoginResponse, error) {
+	login, err := s.completeLogin(ctx, user, client)
+	if err != nil {
+		return nil, err
+	}
+
+	if !login.MFARequired {
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginSuccess)
+	}
+
+	return login, nil
+}
+
+// completeLogin runs once the first factor checked out, it either opens a
+// session or asks for the second factor.
+func (s *AuthService) completeLogin(ctx context.Context, user *postgresql.User, client models.Client) (*models.LoginResponse, error) {
+	mfa, err := s.storage.MFA.GetByUser(ctx, user.ID)
+	if err != nil && !errors.Is(err, postgresql.ErrNotFound) {
+		return nil, err
+	}
+
+	// second step is required, hand out a challenge instead of a session
+	if mfa.IsEnabled() {
+		mfaToken, err := s.auth.GenerateToken(jwt.MapClaims{
+			"sub": user.ID,
+			"typ": mfaTokenType,
+			"exp": time.Now().Add(mfaTokenExp).Unix(),
+			"iat": time.Now().Unix(),
+			"nbf": time.Now().Unix(),
+			"iss": env.GetString("JWT_ISS", "SocialNetwork"),
+			"aud": env.GetString("JWT_ISS", "SocialNetwork"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.LoginResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	token, err := s.createSession(ctx, user.ID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginResponse{
+		TokenResponse: token,
+	}, nil
+}
+
+func (s *AuthService) VerifyMFA(ctx context.Context, payload *models.MFAVerifyPayload) (*models.TokenResponse, error) {
+	jwtToken, err := s.auth.ValidateToken(payload.MFAToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	claims, _ := jwtToken.Claims.(jwt.MapClaims)
+	if claims["typ"] != mfaTokenType {
+		return nil, ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseInt(fmt.Sprintf("%.f", claims["sub"]), 10, 64)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	user, err := s.storage.Users.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// wrong codes count as failed logins of the account, so they back off and
+	// lock out like wrong passwords
+	attempt := &postgresql.LoginAttempt{
+		UserID:    &user.ID,
+		Email:     strings.ToLower(user.Email),
+		IPAddress: payload.IPAddress,
+		UserAgent: truncate(payload.UserAgent, maxUserAgentLength),
+	}
+
+	wait, err := s.loginBackoff(ctx, attempt.Email, attempt.IPAddress)
+	if err != nil {
+		return nil, err
+	}
+
+	if wait > 0 {
+		s.recordLoginAttempt(ctx, attempt, postgresql.LoginLocked)
+		return nil, fmt.Errorf("%w, try again in %s", ErrTooManyAttempts, wait.Round(time.Second))
+	}
+
+	if err := verifyMFACode(ctx, s.storage, user.ID, payload.Code); err != nil {
+		if errors.Is(err, ErrInvalidMFACode) || errors.Is(err, ErrMFAAlreadyAccepted) {
+			s.recordLoginAttempt(ctx, attempt, postgresql.LoginFailed)
+		}
+		return nil, err
+	}
+
+	s.recordLoginAttempt(ctx, attempt, postgresql.LoginSuccess)
+	return s.createSession(ctx, user.ID, payload.Client)
+}
+
+func (s *AuthService) createSession(ctx context.Context, userID int64, client models.Client) (*models.TokenResponse, error) {
+	refreshToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	session := postgresql.Session{
+		UserID:    userID,
+		TokenHash: hash,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		IPAddress: client.IPAddress,
+		ExpiresAt: time.Now().Add(refreshTokenExp),
+	}
+
+	if err := s.storage.Sessions.CreateSession(ctx, &session); err != nil {
+		return nil, err
 	}
 
+	s.recordLogin(ctx, &session, client)
+
+	return s.generateTokens(&session, refreshToken)
+}
+
+func (s *AuthService) RefreshToken(ctx context.Context, payload *models.RefreshPayload) (*models.TokenResponse, error) {
+	session, err := s.storage.Sessions.GetByTokenHash(ctx, auth.HashToken(payload.RefreshToken))
+	if err != nil {
+		switch {
+		case errors.Is(err, postgresql.ErrNotFound):
+			return nil, ErrInvalidToken
+		case errors.Is(err, postgresql.ErrTokenReused):
+			// a rotated token showing up again means it leaked, kill the whole session
+			if err := s.storage.Sessions.RevokeSession(ctx, session.ID); err != nil && !errors.Is(err, postgresql.ErrNotFound) {
+				return nil, err
+			}
+			return nil, ErrInvalidToken
+		default:
+			return nil, err
+		}
+	}
+
+	if !session.IsActive() {
+		return nil, ErrInvalidToken
+	}
+
+	refreshToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Sessions.RotateToken(ctx, session, hash, time.Now().Add(refreshTokenExp)); err != nil {
+		if errors.Is(err, postgresql.ErrTokenReused) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	return s.generateTokens(session, refreshToken)
+}
+
+func (s *AuthService) Logout(ctx context.Context, sessionID int64) error {
+	return s.storage.Sessions.RevokeSession(ctx, sessionID)
+}
+
+func (s *AuthService) generateTokens(session *postgresql.Session, refreshToken string) (*models.TokenResponse, error) {
 	claims := jwt.MapClaims{
-		"sub": user.ID,
-		"exp": time.Now().Add(time.Hour * 24 * 3).Unix(),
+		"sub": session.UserID,
+		"sid": session.ID,
+		"exp": time.Now().Add(accessTokenExp).Unix(),
 		"iat": time.Now().Unix(),
 		"nbf": time.Now().Unix(),
 		"iss": env.GetString("JWT_ISS", "SocialNetwork"),
@@ -73,8 +323,62 @@ func (s *AuthService) LoginUser(ctx context.Context, payload *models.LoginPayloa
 
 	token, err := s.auth.GenerateToken(claims)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return &models.TokenResponse{
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(accessTokenExp.Seconds()),
+	}, nil
+}
+
+// ForgotPassword emails a single-use reset link. Unknown emails are ignored
+// so the endpoint can't be used to find out who has an account.
+func (s *AuthService) ForgotPassword(ctx context.Context, payload *models.ForgotPasswordPayload) error {
+	user, err := s.storage.Users.GetByEmail(ctx, payload.Email)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	plainToken, hash, err := auth.NewOpaqueToken()
+	if err != nil {
+		return err
 	}
 
-	return token, nil
+	if err := s.storage.Passwords.CreateReset(ctx, user.ID, hash, passwordResetExp); err != nil {
+		return err
+	}
+
+	data := struct {
+		Username  string
+		ResetURL  string
+		ExpiresIn string
+	}{
+		Username:  user.Username,
+		ResetURL:  fmt.Sprintf("%s/reset-password/%s", env.GetString("FRONTEND_URL", "http://localhost:3000"), plainToken),
+		ExpiresIn: passwordResetExp.String(),
+	}
+
+	return s.mailer.Send(mailer.PasswordResetTemplate, user.Username, user.Email, data)
+}
+
+func (s *AuthService) ResetPassword(ctx context.Context, payload *models.ResetPasswordPayload) error {
+	var password postgresql.Password
+	if err := password.Set(payload.Password); err != nil {
+		return err
+	}
+
+	if err := s.storage.Passwords.ResetPassword(ctx, auth.HashToken(payload.Token), password.Hash); err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	return nil
 }
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+const (
+	// maxCommentDepth is how deep replies nest, top level comments are depth 0.
+	maxCommentDepth = 3
+	// commentPreview is how many comments come with a post.
+	commentPreview = 3
+)
+
+var ErrCommentTooDeep = fmt.Errorf("replies can't be nested more than %d levels deep", maxCommentDepth)
+
+// encodeCommentCursor packs the sort, and the sort key and id of the last
+// comment of a page.
+func encodeCommentCursor(sort, key string, id int64) *string {
+	raw := sort + "|" + key + "|" + strconv.FormatInt(id, 10)
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(raw))
+	return &cursor
+}
+
+// decodeCommentCursor only accepts a cursor of a page in the same sort, with
+// a key of the type that sort compares on, so nothing malformed reaches the
+// query.
+func decodeCommentCursor(cursor, sort string) (*postgresql.CommentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 3 || parts[0] != sort {
+		return nil, ErrInvalidCursor
+	}
+
+	key := parts[1]
+	if sort == postgresql.CommentsTop {
+		count, err := strconv.ParseInt(key, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		key = strconv.FormatInt(count, 10)
+	} else {
+		createdAt, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		key = createdAt.Format(time.RFC3339Nano)
+	}
+
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &postgresql.CommentCursor{Key: key, ID: id}, nil
+}
+
+// toCommentResponse blanks the content of hidden and deleted comments, they
+// are only kept as placeholders for their replies.
+func toCommentResponse(c postgresql.Comment) models.CommentResponse {
+	reactions := c.Reactions
+	if reactions == nil {
+		reactions = map[string]int64{}
+	}
+
+	resp := models.CommentResponse{
+		ID:           c.ID,
+		ParentID:     c.ParentID,
+		Depth:        c.Depth,
+		UserID:       c.UserID,
+		Username:     c.Username,
+		ImageURL:     c.ImageURL,
+		Content:      c.Content,
+		Status:       c.Status,
+		IsEdited:     c.IsEdited,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+		ReplyCount:   c.ReplyCount,
+		LikeCount:    reactions[postgresql.ReactionLike],
+		DislikeCount: reactions[postgresql.ReactionDislike],
+		Reactions:    reactions,
+	}
+	if c.Status != postgresql.CommentVisible {
+		resp.Content = ""
+	}
+
+	return resp
+}
+
+// CreateReply answers the parent comment, which belongs to the same post.
+func (s *FeedService) CreateReply(ctx context.Context, parent *postgresql.Comment, p *models.CommentPayload) (*models.CommentResponse, error) {
+	if parent.Status == postgresql.CommentHidden {
+		return nil, postgresql.ErrNotFound
+	}
+
+	if parent.Depth >= maxCommentDepth {
+		return nil, ErrCommentTooDeep
+	}
+
+	comment := postgresql.Comment{
+		UserID:   p.UserID,
+		PostID:   p.PostID,
+		Content:  p.Content,
+		ParentID: &parent.ID,
+		Depth:    parent.Depth + 1,
+		Status:   postgresql.CommentVisible,
+	}
+
+	if err := s.storage.Comments.CreateComments(ctx, &comment); err != nil {
+		return nil, err
+	}
+
+	resp := toCommentResponse(comment)
+	return &resp, nil
+}
+
+// GetComments returns a page of the top level comments of the post, or of
+// the replies to parentID, each with the first replies under it. The cursor
+// is the next_cursor of the previous page, it only makes sense with the same
+// sort.
+func (s *FeedService) GetComments(ctx context.Context, postID int64, parentID *int64, page postgresql.CommentPage, cursor string, replies int) (models.CommentsResponse, error) {
+	if cursor != "" {
+		after, err := decodeCommentCursor(cursor, page.Sort)
+		if err != nil {
+			return models.CommentsResponse{}, err
+		}
+		page.After = after
+	}
+
+	threads, err := s.storage.Comments.GetThreads(ctx, postID, parentID, page, replies)
+	if err != nil {
+		return models.CommentsResponse{}, err
+	}
+
+	resp := models.CommentsResponse{Comments: make([]models.CommentResponse, 0, len(threads))}
+	for _, t := range threads {
+		comment := toCommentResponse(t.Comment)
+		for _, r := range t.Replies {
+			comment.Replies = append(comment.Replies, toCommentResponse(r))
+		}
+		resp.Comments = append(resp.Comments, comment)
+	}
+
+	// a full page may have more behind it
+	if len(threads) == page.Limit {
+		last := threads[len(threads)-1].Comment
+		resp.NextCursor = encodeCommentCursor(page.Sort, last.SortKey(page.Sort), last.ID)
+	}
+
+	return resp, nil
+}
+
+// ReactComment toggles the reaction of the user on the comment, hidden
+// comments can't be reacted to.
+func (s *FeedService) ReactComment(ctx context.Context, comment *postgresql.Comment, p *models.UserActivitiesPayload) error {
+	if comment.Status == postgresql.CommentHidden {
+		return postgresql.ErrNotFound
+	}
+
+	reaction := postgresql.Activities{
+		UserID:    p.UserID,
+		CommentID: &p.CommentID,
+		Reaction:  p.Reaction,
+	}
+
+	return s.storage.Activities.ReactComment(ctx, &reaction)
+}
+
+// UpdateComment is for the author only, the content it replaces is kept.
+func (s *FeedService) UpdateComment(ctx context.Context, actor *postgresql.User, comment *postgresql.Comment, payload *models.CommentUpdatePayload) (*models.CommentResponse, error) {
+	if payload.Content != comment.Content {
+		comment.Content = payload.Content
+		if err := s.storage.Comments.UpdateComment(ctx, comment, actor.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := toCommentResponse(*comment)
+	return &resp, nil
+}
+
+// DeleteComment removes the comment, removing someone else's comment is
+// audited with a copy of it.
+func (s *FeedService) DeleteComment(ctx context.Context, actor *postgresql.User, comment *postgresql.Comment, client models.Client) error {
+	var event *postgresql.AuditEvent
+	if actor.ID != comment.UserID {
+		var err error
+		event, err = newAuditEvent(actor, AuditCommentDelete, postgresql.AuditTargetComment, comment.ID,
+			map[string]any{
+				"user_id": comment.UserID,
+				"post_id": comment.PostID,
+				"content": comment.Content,
+			},
+			nil,
+			client,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.storage.Comments.DeleteComment(ctx, comment.ID, actor.ID, event)
+}
+
+// SetCommentHidden hides the comment or shows it again, it is a moderation
+// action so it is always audited.
+func (s *FeedService) SetCommentHidden(ctx context.Context, actor *postgresql.User, comment *postgresql.Comment, hidden bool, client models.Client) error {
+	action, hiddenBy := AuditCommentUnhide, (*int64)(nil)
+	if hidden {
+		action, hiddenBy = AuditCommentHide, &actor.ID
+	}
+
+	if hidden == (comment.Status == postgresql.CommentHidden) {
+		return nil
+	}
+
+	event, err := newAuditEvent(actor, action, postgresql.AuditTargetComment, comment.ID,
+		map[string]any{"status": comment.Status},
+		map[string]any{"user_id": comment.UserID, "post_id": comment.PostID},
+		client,
+	)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Comments.SetHidden(ctx, comment.ID, hiddenBy, event)
+}
+
+func (s *FeedService) GetCommentRevisions(ctx context.Context, comment *postgresql.Comment) ([]models.CommentRevisionResponse, error) {
+	revisions, err := s.storage.Comments.GetRevisions(ctx, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.CommentRevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		resp = append(resp, models.CommentRevisionResponse{
+			ID:               r.ID,
+			Content:          r.Content,
+			EditedBy:         r.EditedBy,
+			VersionCreatedAt: r.VersionCreatedAt,
+			ReplacedAt:       r.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+func TestCommentCursorRoundTrip(t *testing.T) {
+	createdAt := time.Date(2024, 5, 1, 10, 30, 0, 123456000, time.UTC).Format(time.RFC3339Nano)
+
+	cursor := encodeCommentCursor(postgresql.CommentsNewest, createdAt, 42)
+	got, err := decodeCommentCursor(*cursor, postgresql.CommentsNewest)
+	if err != nil {
+		t.Fatalf("decodeCommentCursor() error = %v", err)
+	}
+
+	if got.Key != createdAt || got.ID != 42 {
+		t.Fatalf("decodeCommentCursor() = %+v, want key %q and id 42", got, createdAt)
+	}
+}
+
+func TestCommentCursorRejectsBadCursors(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name   string
+		cursor string
+		sort   string
+	}{
+		{"not base64", "%%%", postgresql.CommentsNewest},
+		{"no sort", encode("x|1"), postgresql.CommentsNewest},
+		{"bad time", encode("newest|x|1"), postgresql.CommentsNewest},
+		{"bad count", encode("top|x|1"), postgresql.CommentsTop},
+		{"bad id", encode("top|3|x"), postgresql.CommentsTop},
+		{"other sort", *encodeCommentCursor(postgresql.CommentsTop, "3", 1), postgresql.CommentsNewest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCommentCursor(tt.cursor, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("decodeCommentCursor() error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
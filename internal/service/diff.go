@@ -0,0 +1,90 @@
+package service
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+)
+
+const (
+	diffEqual  = "equal"
+	diffInsert = "insert"
+	diffDelete = "delete"
+
+	// the lcs table grows with both sides, past this the diff is just
+	// everything removed and everything added
+	maxDiffCells = 4_000_000
+)
+
+// diffLines returns a line based diff turning from into to.
+func diffLines(from, to string) []models.DiffLine {
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+	diff := []models.DiffLine{}
+
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			diff = append(diff, models.DiffLine{Op: diffDelete, Text: line})
+		}
+		for _, line := range b {
+			diff = append(diff, models.DiffLine{Op: diffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, models.DiffLine{Op: diffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, models.DiffLine{Op: diffDelete, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, models.DiffLine{Op: diffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, models.DiffLine{Op: diffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, models.DiffLine{Op: diffInsert, Text: b[j]})
+	}
+
+	return diff
+}
+
+// diffTags returns the tags only in to and the tags only in from.
+func diffTags(from, to []string) (added, removed []string) {
+	added, removed = []string{}, []string{}
+	for _, tag := range to {
+		if !slices.Contains(from, tag) {
+			added = append(added, tag)
+		}
+	}
+	for _, tag := range from {
+		if !slices.Contains(to, tag) {
+			removed = append(removed, tag)
+		}
+	}
+
+	return added, removed
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+const publishBatchSize = 100
+
+var ErrInvalidPublishAt = errors.New("scheduled posts need a publish_at in the future")
+
+// schedule checks the status and publish time of a post, only scheduled
+// posts keep a publish time.
+func schedule(status string, publishAt *time.Time) (string, *time.Time, error) {
+	switch status {
+	case "", postgresql.PostPublished:
+		return postgresql.PostPublished, nil, nil
+	case postgresql.PostScheduled:
+		if publishAt == nil || !publishAt.After(time.Now()) {
+			return "", nil, ErrInvalidPublishAt
+		}
+		return status, publishAt, nil
+	default:
+		return status, nil, nil
+	}
+}
+
+func (s *PostService) GetDrafts(ctx context.Context, userID int64) ([]models.DraftResponse, error) {
+	posts, err := s.storage.Posts.GetDrafts(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.DraftResponse, 0, len(posts))
+	for _, p := range posts {
+		resp = append(resp, toDraftResponse(&p))
+	}
+
+	return resp, nil
+}
+
+func (s *PostService) GetDraft(ctx context.Context, userID, postID int64) (*models.DraftResponse, error) {
+	post, err := s.storage.Posts.GetDraft(ctx, userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toDraftResponse(post)
+	return &resp, nil
+}
+
+func (s *PostService) UpdateDraft(ctx context.Context, userID, postID int64, payload *models.DraftUpdatePayload) (*models.DraftResponse, error) {
+	post, err := s.storage.Posts.GetDraft(ctx, userID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Title != nil {
+		post.Title = *payload.Title
+	}
+
+	if payload.Content != nil {
+		post.Content = *payload.Content
+	}
+
+	if payload.Tags != nil {
+		if post.Tags, err = normalizeTags(*payload.Tags); err != nil {
+			return nil, err
+		}
+	}
+
+	if payload.Status != nil {
+		post.Status = *payload.Status
+	}
+
+	if payload.PublishAt != nil {
+		post.PublishAt = payload.PublishAt
+	}
+
+	if payload.Visibility != nil {
+		post.Visibility = *payload.Visibility
+	}
+
+	if post.Status, post.PublishAt, err = schedule(post.Status, post.PublishAt); err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Posts.UpdateDraft(ctx, post); err != nil {
+		return nil, err
+	}
+
+	resp := toDraftResponse(post)
+	return &resp, nil
+}
+
+// DeleteDraft moves the draft to the trash like any other post.
+func (s *PostService) DeleteDraft(ctx context.Context, userID, postID int64) error {
+	if _, err := s.storage.Posts.GetDraft(ctx, userID, postID); err != nil {
+		return err
+	}
+
+	return s.storage.Posts.DeletePost(ctx, postID, userID, nil)
+}
+
+// PublishScheduledPosts publishes every scheduled post that is due, it
+// returns how many were published.
+func (s *PostService) PublishScheduledPosts(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		published, err := s.storage.Posts.PublishDue(ctx, publishBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += len(published)
+
+		if len(published) < publishBatchSize {
+			return total, nil
+		}
+	}
+}
+
+func toDraftResponse(p *postgresql.Post) models.DraftResponse {
+	draft := models.DraftResponse{
+		ID:         p.ID,
+		Title:      p.Title,
+		Content:    p.Content,
+		Tags:       p.Tags,
+		Images:     []string{},
+		Status:     p.Status,
+		PublishAt:  p.PublishAt,
+		Visibility: p.Visibility,
+		CreatedAt:  p.CreatedAt,
+		UpdatedAt:  p.UpdatedAt,
+	}
+	if draft.Tags == nil {
+		draft.Tags = []string{}
+	}
+
+	for _, img := range p.Images {
+		draft.Images = append(draft.Images, img.ImageURL)
+	}
+
+	return draft
+}
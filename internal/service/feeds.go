@@ -19,22 +19,17 @@ func (s *FeedService) GetFeeds(ctx context.Context, userID int64, pf postgresql.
 	}
 
 	var (
-		wg                                      sync.WaitGroup
-		commentCount, likesCount, disLikedCount int64
-		posts                                   []models.PostsResponse
+		wg           sync.WaitGroup
+		commentCount int64
+		reactions    map[string]int64
+		posts        []models.PostsResponse
 	)
 
 	for _, p := range respPost {
-		var images []models.ImageResponse
-		errChan := make(chan error, 3)
-		for _, i := range p.Post.Images {
-			images = append(images, models.ImageResponse{
-				ImageUrl:  i.ImageURL,
-				ImageName: i.ImageName,
-			})
-		}
+		images := imageResponses(p.Post.Images)
+		errChan := make(chan error, 2)
 
-		wg.Add(3)
+		wg.Add(2)
 		go func() {
 			defer wg.Done()
 			count, err := s.storage.Comments.GetCommentCountByPost(ctx, p.Post.ID)
@@ -48,24 +43,13 @@ func (s *FeedService) GetFeeds(ctx context.Context, userID int64, pf postgresql.
 
 		go func() {
 			defer wg.Done()
-			count, err := s.storage.Activities.GetLikesByPost(ctx, p.Post.ID)
-			if err != nil {
-				errChan <- err
-				return
-			}
-
-			likesCount = count
-		}()
-
-		go func() {
-			defer wg.Done()
-			count, err := s.storage.Activities.GetDislikesByPost(ctx, p.Post.ID)
+			counts, err := s.storage.Activities.GetReactionsByPost(ctx, p.Post.ID)
 			if err != nil {
 				errChan <- err
 				return
 			}
 
-			disLikedCount = count
+			reactions = counts
 		}()
 
 		wg.Wait()
@@ -81,11 +65,7 @@ func (s *FeedService) GetFeeds(ctx context.Context, userID int64, pf postgresql.
 			Content:  p.Post.Content,
 			Tags:     p.Post.Tags,
 			Images:   images,
-			MetaData: models.MetaData{
-				CommentCount: commentCount,
-				LikeCount:    likesCount,
-				DislikeCount: disLikedCount,
-			},
+			MetaData: postMetaData(commentCount, reactions),
 		}
 
 		posts = append(posts, post)
@@ -96,21 +76,20 @@ func (s *FeedService) GetFeeds(ctx context.Context, userID int64, pf postgresql.
 	}, nil
 }
 
-func (s *FeedService) GetFeed(ctx context.Context, postID int64) (models.PostResponse, error) {
-	respPost, err := s.storage.Posts.GetPostByID(ctx, postID)
-	if err != nil {
-		return models.PostResponse{}, err
-	}
+// GetFeed builds the response for a post already loaded, and so already
+// checked against its visibility, by the post middleware.
+func (s *FeedService) GetFeed(ctx context.Context, respPost *postgresql.Post) (models.PostResponse, error) {
+	postID := respPost.ID
 
 	var (
-		wg                                      sync.WaitGroup
-		commentCount, likesCount, disLikedCount int64
-		allComments                             []models.CommentResponse
-		images                                  []models.ImageResponse
+		wg           sync.WaitGroup
+		commentCount int64
+		reactions    map[string]int64
+		allComments  []models.CommentResponse
 	)
 
-	errChan := make(chan error, 4)
-	wg.Add(4)
+	errChan := make(chan error, 3)
+	wg.Add(3)
 	go func() {
 		defer wg.Done()
 		count, err := s.storage.Comments.GetCommentCountByPost(ctx, postID)
@@ -124,29 +103,18 @@ func (s *FeedService) GetFeed(ctx context.Context, postID int64) (models.PostRes
 
 	go func() {
 		defer wg.Done()
-		count, err := s.storage.Activities.GetLikesByPost(ctx, postID)
+		counts, err := s.storage.Activities.GetReactionsByPost(ctx, postID)
 		if err != nil {
 			errChan <- err
 			return
 		}
 
-		likesCount = count
+		reactions = counts
 	}()
 
 	go func() {
 		defer wg.Done()
-		count, err := s.storage.Activities.GetDislikesByPost(ctx, postID)
-		if err != nil {
-			errChan <- err
-			return
-		}
-
-		disLikedCount = count
-	}()
-
-	go func() {
-		defer wg.Done()
-		comments, err := s.getComments(ctx, postID)
+		comments, err := s.getCommentPreview(ctx, postID)
 		if err != nil {
 			errChan <- err
 			return
@@ -160,79 +128,86 @@ func (s *FeedService) GetFeed(ctx context.Context, postID int64) (models.PostRes
 		return models.PostResponse{}, err
 	}
 
-	for _, i := range respPost.Images {
-		image := models.ImageResponse{
-			ImageUrl:  i.ImageURL,
-			ImageName: i.ImageName,
-		}
-
-		images = append(images, image)
-	}
-
 	return models.PostResponse{
-		ID:        respPost.ID,
-		Title:     respPost.Title,
-		Content:   respPost.Content,
-		Tags:      respPost.Tags,
-		Images:    images,
-		IsEdited:  respPost.IsEdited,
-		CreatedAt: respPost.CreatedAt,
-		UpdatedAt: respPost.UpdatedAt,
+		ID:         respPost.ID,
+		Title:      respPost.Title,
+		Content:    respPost.Content,
+		Tags:       respPost.Tags,
+		Images:     imageResponses(respPost.Images),
+		IsEdited:   respPost.IsEdited,
+		Visibility: respPost.Visibility,
+		CreatedAt:  respPost.CreatedAt,
+		UpdatedAt:  respPost.UpdatedAt,
 		User: models.UserFeedResponse{
 			Username: respPost.User.Username,
-			UserID:   respPost.ID,
+			UserID:   respPost.UserID,
 		},
 		Comments: allComments,
-		MetaData: models.MetaData{
-			CommentCount: commentCount,
-			LikeCount:    likesCount,
-			DislikeCount: disLikedCount,
-		},
+		MetaData: postMetaData(commentCount, reactions),
 	}, nil
 }
 
-func (s *FeedService) getComments(ctx context.Context, postID int64) ([]models.CommentResponse, error) {
-	commentResp, err := s.storage.Comments.GetCommentsByPost(ctx, postID)
+// getCommentPreview is the newest few comments shown with a post, the rest
+// are paged through GetComments.
+func (s *FeedService) getCommentPreview(ctx context.Context, postID int64) ([]models.CommentResponse, error) {
+	threads, err := s.storage.Comments.GetThreads(ctx, postID, nil, postgresql.CommentPage{
+		Sort:  postgresql.CommentsNewest,
+		Limit: commentPreview,
+	}, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	var (
-		comments []models.CommentResponse
-		comment  models.CommentResponse
-	)
+	comments := make([]models.CommentResponse, 0, len(threads))
+	for _, t := range threads {
+		comments = append(comments, toCommentResponse(t.Comment))
+	}
 
-	for _, c := range commentResp {
-		comment = models.CommentResponse{
-			UserID:    c.ID,
-			Content:   c.Content,
-			IsEdited:  c.IsEdited,
-			CreatedAt: c.CreatedAt,
-			UpdatedAt: c.UpdatedAt,
-		}
+	return comments, nil
+}
 
-		comments = append(comments, comment)
+// postMetaData keeps like_count and dislike_count next to the breakdown by
+// kind.
+func postMetaData(commentCount int64, reactions map[string]int64) models.MetaData {
+	if reactions == nil {
+		reactions = map[string]int64{}
 	}
 
-	return comments, nil
+	return models.MetaData{
+		CommentCount: commentCount,
+		LikeCount:    reactions[postgresql.ReactionLike],
+		DislikeCount: reactions[postgresql.ReactionDislike],
+		Reactions:    reactions,
+	}
 }
 
-func (s *FeedService) LikePost(ctx context.Context, p *models.UserActivitiesPayload) error {
-	liked := postgresql.Activities{
-		UserID: p.UserID,
-		PostID: p.PostID,
+func (s *FeedService) GetReactions(ctx context.Context) ([]models.ReactionResponse, error) {
+	reactions, err := s.storage.Activities.GetReactions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.ReactionResponse, 0, len(reactions))
+	for _, r := range reactions {
+		resp = append(resp, models.ReactionResponse{
+			Kind:  r.Kind,
+			Emoji: r.Emoji,
+		})
 	}
 
-	return s.storage.Activities.ToggleLikePost(ctx, &liked)
+	return resp, nil
 }
 
-func (s *FeedService) DislikePost(ctx context.Context, p *models.UserActivitiesPayload) error {
-	disliked := postgresql.Activities{
-		UserID: p.UserID,
-		PostID: p.PostID,
+// ReactPost toggles the reaction of the user on the post, replacing the one
+// they had. Liking and disliking are two of the reactions.
+func (s *FeedService) ReactPost(ctx context.Context, p *models.UserActivitiesPayload) error {
+	reaction := postgresql.Activities{
+		UserID:   p.UserID,
+		PostID:   p.PostID,
+		Reaction: p.Reaction,
 	}
 
-	return s.storage.Activities.ToggleDislikePost(ctx, &disliked)
+	return s.storage.Activities.ReactPost(ctx, &reaction)
 }
 
 func (s *FeedService) CreateCommentPost(ctx context.Context, p *models.CommentPayload) error {
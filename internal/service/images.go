@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/cldnary"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+// imageAuditEvent audits image changes made by someone other than the owner,
+// the same way edits of the post itself are.
+func imageAuditEvent(actor *postgresql.User, post *postgresql.Post, action string, before, after any, client models.Client) (*postgresql.AuditEvent, error) {
+	if actor.ID == post.UserID {
+		return nil, nil
+	}
+
+	return newAuditEvent(actor, action, postgresql.AuditTargetPost, post.ID, before, after, client)
+}
+
+func imageResponses(images []postgresql.ImagePost) []models.ImageResponse {
+	var resp []models.ImageResponse
+	for _, i := range images {
+		resp = append(resp, models.ImageResponse{
+			ID:        i.ID,
+			ImageUrl:  i.ImageURL,
+			ImageName: i.ImageName,
+			Position:  i.Position,
+			AltText:   i.AltText,
+		})
+	}
+
+	return resp
+}
+
+// AddImages uploads the images and appends them to the post, uploads are
+// removed again if the post can't be updated.
+func (s *PostService) AddImages(ctx context.Context, actor *postgresql.User, post *postgresql.Post, payload *models.ImagesPayload) ([]models.ImageResponse, error) {
+	images := []postgresql.ImagePost{}
+	publicIDs := []string{}
+
+	rollback := func(err error) error {
+		for _, id := range publicIDs {
+			if errDelete := s.cloudinary.DeleteImage(ctx, id); errDelete != nil {
+				return fmt.Errorf("rollback failed: %w", errDelete)
+			}
+		}
+		return err
+	}
+
+	for i, image := range payload.Images {
+		imgUrl, publicID, err := s.cloudinary.UploadImage(ctx, image, folderPost)
+		if err != nil {
+			return nil, rollback(err)
+		}
+		publicIDs = append(publicIDs, publicID)
+
+		img := postgresql.ImagePost{
+			ImageURL:  imgUrl,
+			ImageName: generateFilename(post.Title, len(post.Images)+i+1),
+			PublicID:  publicID,
+		}
+		if i < len(payload.AltTexts) {
+			img.AltText = payload.AltTexts[i]
+		}
+		images = append(images, img)
+	}
+
+	event, err := imageAuditEvent(actor, post, AuditPostImageAdd, nil, map[string]any{"public_ids": publicIDs}, payload.Client)
+	if err != nil {
+		return nil, rollback(err)
+	}
+
+	if err := s.storage.Posts.AddImages(ctx, post.ID, images, event); err != nil {
+		return nil, rollback(err)
+	}
+
+	return s.getImages(ctx, post.ID)
+}
+
+// RemoveImage detaches the image from the post and deletes the remote file.
+func (s *PostService) RemoveImage(ctx context.Context, actor *postgresql.User, post *postgresql.Post, imageID int64, client models.Client) error {
+	event, err := imageAuditEvent(actor, post, AuditPostImageRemove, map[string]any{"image_id": imageID}, nil, client)
+	if err != nil {
+		return err
+	}
+
+	image, err := s.storage.Posts.RemoveImage(ctx, post.ID, imageID, event)
+	if err != nil {
+		return err
+	}
+
+	// images uploaded before the public id was stored only have their url
+	publicID := image.PublicID
+	if publicID == "" {
+		var ok bool
+		if publicID, ok = cldnary.PublicIDFromURL(image.ImageURL); !ok {
+			return nil
+		}
+	}
+
+	// the row is gone already, a failure only leaves an orphaned file behind
+	if err := s.cloudinary.DeleteImage(ctx, publicID); err != nil {
+		log.Printf("failed to delete image %s: %v", publicID, err)
+	}
+
+	return nil
+}
+
+func (s *PostService) ReorderImages(ctx context.Context, actor *postgresql.User, post *postgresql.Post, payload *models.ImageOrderPayload) ([]models.ImageResponse, error) {
+	before := []int64{}
+	for _, i := range post.Images {
+		before = append(before, i.ID)
+	}
+
+	event, err := imageAuditEvent(actor, post, AuditPostImageReorder, map[string]any{"image_ids": before}, map[string]any{"image_ids": payload.ImageIDs}, payload.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Posts.ReorderImages(ctx, post.ID, payload.ImageIDs, event); err != nil {
+		return nil, err
+	}
+
+	return s.getImages(ctx, post.ID)
+}
+
+func (s *PostService) UpdateImage(ctx context.Context, actor *postgresql.User, post *postgresql.Post, imageID int64, payload *models.ImageUpdatePayload) error {
+	event, err := imageAuditEvent(actor, post, AuditPostImageUpdate, nil, map[string]any{"image_id": imageID, "alt_text": payload.AltText}, payload.Client)
+	if err != nil {
+		return err
+	}
+
+	return s.storage.Posts.UpdateImageAltText(ctx, post.ID, imageID, payload.AltText, event)
+}
+
+func (s *PostService) getImages(ctx context.Context, postID int64) ([]models.ImageResponse, error) {
+	images, err := s.storage.Posts.GetImages(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return imageResponses(images), nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	loginAttemptWindow     = time.Hour
+	loginLockoutDuration   = time.Minute * 15
+	accountFreeAttempts    = 3
+	accountLockoutAttempts = 10
+	ipFreeAttempts         = 20
+	ipLockoutAttempts      = 100
+	maxUserAgentLength     = 512
+)
+
+var (
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrTooManyAttempts    = errors.New("too many failed login attempts")
+
+	dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), bcrypt.DefaultCost)
+)
+
+// loginBackoff returns how long the caller has to wait before the next
+// attempt, the longer of the account and the ip address delay.
+func (s *AuthService) loginBackoff(ctx context.Context, email, ip string) (time.Duration, error) {
+	since := time.Now().Add(-loginAttemptWindow)
+
+	failures, lastAt, err := s.storage.LoginAttempts.CountFailuresByEmail(ctx, email, since)
+	if err != nil {
+		return 0, err
+	}
+	wait := time.Until(lastAt.Add(loginDelay(failures, accountFreeAttempts, accountLockoutAttempts)))
+
+	if ip != "" {
+		failures, lastAt, err = s.storage.LoginAttempts.CountFailuresByIP(ctx, ip, since)
+		if err != nil {
+			return 0, err
+		}
+
+		if ipWait := time.Until(lastAt.Add(loginDelay(failures, ipFreeAttempts, ipLockoutAttempts))); ipWait > wait {
+			wait = ipWait
+		}
+	}
+
+	return wait, nil
+}
+
+// loginDelay doubles the delay with every failure past the free ones and
+// locks out for loginLockoutDuration once the lockout threshold is reached.
+func loginDelay(failures, free, lockout int) time.Duration {
+	switch {
+	case failures < free:
+		return 0
+	case failures >= lockout:
+		return loginLockoutDuration
+	}
+
+	if exp := failures - free; exp < 10 {
+		if delay := time.Second << exp; delay < loginLockoutDuration {
+			return delay
+		}
+	}
+
+	return loginLockoutDuration
+}
+
+// recordLoginAttempt never fails the login, losing an audit row is better
+// than locking everyone out when the insert breaks.
+func (s *AuthService) recordLoginAttempt(ctx context.Context, attempt *postgresql.LoginAttempt, result string) {
+	attempt.Result = result
+
+	if err := s.storage.LoginAttempts.CreateAttempt(ctx, attempt); err != nil {
+		log.Printf("failed to record login attempt for %s: %v", attempt.Email, err)
+	}
+}
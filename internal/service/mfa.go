@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+const recoveryCodeCount = 10
+
+var (
+	ErrInvalidMFACode     = errors.New("invalid two-factor code")
+	ErrMFAAlreadyEnabled  = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled     = errors.New("two-factor authentication is not enrolled")
+	ErrMFAAlreadyAccepted = errors.New("two-factor code has already been used")
+)
+
+type MFAService struct {
+	storage *postgresql.Storage
+}
+
+func (s *MFAService) EnrollTOTP(ctx context.Context, user *postgresql.User) (*models.TOTPEnrollResponse, error) {
+	secret, err := auth.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.MFA.SetPendingSecret(ctx, user.ID, secret); err != nil {
+		if errors.Is(err, postgresql.ErrConflict) {
+			return nil, ErrMFAAlreadyEnabled
+		}
+		return nil, err
+	}
+
+	return &models.TOTPEnrollResponse{
+		Secret:     secret,
+		OtpauthURI: auth.TOTPURI(env.GetString("TOTP_ISSUER", "SocialNetwork"), user.Email, secret),
+	}, nil
+}
+
+func (s *MFAService) ConfirmTOTP(ctx context.Context, user *postgresql.User, payload *models.MFACodePayload) (*models.RecoveryCodesResponse, error) {
+	mfa, err := s.storage.MFA.GetByUser(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return nil, ErrMFANotEnrolled
+		}
+		return nil, err
+	}
+
+	if mfa.IsEnabled() {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	step, ok := auth.ValidateTOTP(mfa.Secret, payload.Code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := make([][]byte, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, auth.HashToken(code))
+	}
+
+	if err := s.storage.MFA.Enable(ctx, user.ID, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return &models.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+func (s *MFAService) DisableTOTP(ctx context.Context, user *postgresql.User, payload *models.MFACodePayload) error {
+	if err := verifyMFACode(ctx, s.storage, user.ID, payload.Code); err != nil {
+		return err
+	}
+
+	return s.storage.MFA.Disable(ctx, user.ID)
+}
+
+// verifyMFACode accepts either a current totp code or an unused recovery
+// code, both are single use.
+func verifyMFACode(ctx context.Context, storage *postgresql.Storage, userID int64, code string) error {
+	mfa, err := storage.MFA.GetByUser(ctx, userID)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return ErrMFANotEnrolled
+		}
+		return err
+	}
+
+	if !mfa.IsEnabled() {
+		return ErrMFANotEnrolled
+	}
+
+	if step, ok := auth.ValidateTOTP(mfa.Secret, code, time.Now()); ok {
+		if err := storage.MFA.UseStep(ctx, userID, step); err != nil {
+			if errors.Is(err, postgresql.ErrTokenReused) {
+				return ErrMFAAlreadyAccepted
+			}
+			return err
+		}
+		return nil
+	}
+
+	hash := auth.HashToken(auth.NormalizeRecoveryCode(code))
+	if err := storage.MFA.UseRecoveryCode(ctx, userID, hash); err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return ErrInvalidMFACode
+		}
+		return err
+	}
+
+	return nil
+}
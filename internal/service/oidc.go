@@ -0,0 +1,320 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/oidc"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcStateExp      = time.Minute * 10
+	oidcLinkTokenExp  = time.Minute * 5
+	oidcLinkTokenType = "oidc_link"
+)
+
+var (
+	ErrOIDCDisabled      = errors.New("social login is not configured")
+	ErrAccountExists     = errors.New("an account with this email already exists, log in with your password and link the provider from your profile")
+	ErrIdentityLinked    = errors.New("this identity is already linked to another account")
+	ErrOIDCEmailRequired = errors.New("the identity provider did not return a verified email")
+
+	usernameCleaner = regexp.MustCompile(`[^a-z0-9_]+`)
+)
+
+// StartOIDC begins the authorization code flow, linkUserID is set when a
+// logged in user links the provider to their existing account.
+func (s *AuthService) StartOIDC(ctx context.Context, linkUserID int64) (*models.OIDCAuthorizationResponse, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	verifier, challenge, err := oidc.NewPKCE()
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := oidc.NewState()
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := oidc.NewNonce()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storage.Identities.SaveState(ctx, &postgresql.OIDCState{
+		State:        state,
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		LinkUserID:   linkUserID,
+		ExpiresAt:    time.Now().Add(oidcStateExp),
+	}); err != nil {
+		return nil, err
+	}
+
+	authURL, err := s.oidc.AuthCodeURL(ctx, state, nonce, challenge)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCAuthorizationResponse{
+		AuthorizationURL: authURL,
+		State:            state,
+	}, nil
+}
+
+func (s *AuthService) OIDCCallback(ctx context.Context, payload *models.OIDCCallbackPayload) (*models.OIDCCallbackResponse, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	// the state has to come back to the browser that started the flow, a
+	// callback url opened anywhere else would log in or link as someone else
+	if payload.BrowserState == "" || subtle.ConstantTimeCompare([]byte(payload.BrowserState), []byte(payload.State)) != 1 {
+		return nil, ErrInvalidToken
+	}
+
+	state, err := s.storage.Identities.ConsumeState(ctx, payload.State)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	claims, err := s.oidc.Exchange(ctx, payload.Code, state.CodeVerifier, state.Nonce)
+	if err != nil {
+		if errors.Is(err, oidc.ErrInvalidIDToken) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	identity, err := s.storage.Identities.GetByProviderSubject(ctx, s.oidc.Name, claims.Subject)
+	if err != nil && !errors.Is(err, postgresql.ErrNotFound) {
+		return nil, err
+	}
+
+	// the callback is not authenticated, the link waits for the user who
+	// started it to confirm it from their session
+	if state.LinkUserID != 0 {
+		if identity != nil && identity.UserID != state.LinkUserID {
+			return nil, ErrIdentityLinked
+		}
+
+		linkToken, err := s.auth.GenerateToken(jwt.MapClaims{
+			"sub":   state.LinkUserID,
+			"typ":   oidcLinkTokenType,
+			"prv":   s.oidc.Name,
+			"idp":   claims.Subject,
+			"email": claims.Email,
+			"exp":   time.Now().Add(oidcLinkTokenExp).Unix(),
+			"iat":   time.Now().Unix(),
+			"nbf":   time.Now().Unix(),
+			"iss":   env.GetString("JWT_ISS", "SocialNetwork"),
+			"aud":   env.GetString("JWT_ISS", "SocialNetwork"),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		return &models.OIDCCallbackResponse{LinkToken: linkToken}, nil
+	}
+
+	var user *postgresql.User
+	if identity != nil {
+		user, err = s.storage.Users.GetByID(ctx, identity.UserID)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		user, err = s.registerFromIdentity(ctx, claims)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	login, err := s.completeLogin(ctx, user, payload.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCCallbackResponse{
+		LoginResponse: login,
+	}, nil
+}
+
+// ConfirmOIDCLink links the identity of a link token to the account, only
+// the user who started the link can confirm it.
+func (s *AuthService) ConfirmOIDCLink(ctx context.Context, userID int64, payload *models.OIDCLinkPayload) (*models.OIDCCallbackResponse, error) {
+	if s.oidc == nil {
+		return nil, ErrOIDCDisabled
+	}
+
+	jwtToken, err := s.auth.ValidateToken(payload.LinkToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	tokenClaims, _ := jwtToken.Claims.(jwt.MapClaims)
+	if tokenClaims["typ"] != oidcLinkTokenType || tokenClaims["prv"] != s.oidc.Name {
+		return nil, ErrInvalidToken
+	}
+
+	if fmt.Sprintf("%.f", tokenClaims["sub"]) != fmt.Sprint(userID) {
+		return nil, ErrInvalidToken
+	}
+
+	subject, _ := tokenClaims["idp"].(string)
+	email, _ := tokenClaims["email"].(string)
+	if subject == "" {
+		return nil, ErrInvalidToken
+	}
+
+	identity, err := s.storage.Identities.GetByProviderSubject(ctx, s.oidc.Name, subject)
+	if err != nil && !errors.Is(err, postgresql.ErrNotFound) {
+		return nil, err
+	}
+
+	return s.linkIdentity(ctx, userID, identity, &oidc.Claims{
+		Subject: subject,
+		Email:   email,
+	})
+}
+
+func (s *AuthService) linkIdentity(ctx context.Context, userID int64, identity *postgresql.Identity, claims *oidc.Claims) (*models.OIDCCallbackResponse, error) {
+	if identity != nil {
+		if identity.UserID != userID {
+			return nil, ErrIdentityLinked
+		}
+		return &models.OIDCCallbackResponse{Linked: true}, nil
+	}
+
+	if err := s.storage.Identities.CreateIdentity(ctx, &postgresql.Identity{
+		UserID:   userID,
+		Provider: s.oidc.Name,
+		Subject:  claims.Subject,
+		Email:    claims.Email,
+	}); err != nil {
+		if errors.Is(err, postgresql.ErrConflict) {
+			return nil, ErrIdentityLinked
+		}
+		return nil, err
+	}
+
+	return &models.OIDCCallbackResponse{Linked: true}, nil
+}
+
+// registerFromIdentity creates the local account for a first time social
+// login. Existing password accounts are never merged silently, the owner has
+// to link the provider while logged in.
+func (s *AuthService) registerFromIdentity(ctx context.Context, claims *oidc.Claims) (*postgresql.User, error) {
+	if claims.Email == "" || !claims.EmailVerified {
+		return nil, ErrOIDCEmailRequired
+	}
+
+	if _, err := s.storage.Users.GetByEmail(ctx, claims.Email); err == nil {
+		return nil, ErrAccountExists
+	} else if !errors.Is(err, postgresql.ErrNotFound) {
+		return nil, err
+	}
+
+	// the user never sees this password, a reset sets a real one
+	randomPassword, _, err := auth.NewOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	imgURL := claims.Picture
+	if imgURL == "" || len(imgURL) > 255 {
+		imgURL = defaultImage[rand.IntN(len(defaultImage))]
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		user := postgresql.User{
+			Username: usernameFromClaims(claims),
+			Fullname: claims.Name,
+			Email:    claims.Email,
+		}
+		if len(user.Fullname) < 3 {
+			user.Fullname = user.Username
+		}
+
+		if err := user.Password.Set(randomPassword); err != nil {
+			return nil, err
+		}
+
+		identity := postgresql.Identity{
+			Provider: s.oidc.Name,
+			Subject:  claims.Subject,
+			Email:    claims.Email,
+		}
+
+		err := s.storage.Users.CreateWithIdentity(ctx, &user, &postgresql.ImgURL{ImageURL: imgURL}, &identity)
+		switch {
+		case err == nil:
+			return s.storage.Users.GetByID(ctx, user.ID)
+		case errors.Is(err, postgresql.ErrDuplicateUsername):
+			continue
+		case errors.Is(err, postgresql.ErrDuplicateEmail):
+			return nil, ErrAccountExists
+		default:
+			return nil, err
+		}
+	}
+
+	return nil, postgresql.ErrDuplicateUsername
+}
+
+func (s *AuthService) GetIdentities(ctx context.Context, userID int64) ([]models.IdentityResponse, error) {
+	identities, err := s.storage.Identities.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []models.IdentityResponse{}
+	for _, i := range identities {
+		resp = append(resp, models.IdentityResponse{
+			ID:        i.ID,
+			Provider:  i.Provider,
+			Email:     i.Email,
+			CreatedAt: i.CreatedAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AuthService) UnlinkIdentity(ctx context.Context, userID, identityID int64) error {
+	return s.storage.Identities.DeleteIdentity(ctx, userID, identityID)
+}
+
+func usernameFromClaims(claims *oidc.Claims) string {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+
+	base = usernameCleaner.ReplaceAllString(strings.ToLower(base), "")
+	if len(base) < 3 {
+		base = "user"
+	}
+	if len(base) > 200 {
+		base = base[:200]
+	}
+
+	return fmt.Sprintf("%s_%04d", base, rand.IntN(10000))
+}
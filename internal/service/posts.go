@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -19,11 +20,27 @@ type PostService struct {
 }
 
 func (s *PostService) CreatePost(ctx context.Context, payload *models.PostPayload) error {
+	status, publishAt, err := schedule(payload.Status, payload.PublishAt)
+	if err != nil {
+		return err
+	}
+
+	tags, err := normalizeTags(payload.Tags)
+	if err != nil {
+		return err
+	}
+
 	posts := postgresql.Post{
-		UserID:  payload.UserID,
-		Title:   payload.Title,
-		Content: payload.Content,
-		Tags:    payload.Tags,
+		UserID:     payload.UserID,
+		Title:      payload.Title,
+		Content:    payload.Content,
+		Tags:       tags,
+		Status:     status,
+		PublishAt:  publishAt,
+		Visibility: payload.Visibility,
+	}
+	if posts.Visibility == "" {
+		posts.Visibility = postgresql.VisibilityPublic
 	}
 
 	imagesPayloads := []postgresql.ImagePost{}
@@ -41,6 +58,11 @@ func (s *PostService) CreatePost(ctx context.Context, payload *models.PostPayloa
 		imagePayload := postgresql.ImagePost{
 			ImageURL:  imgUrl,
 			ImageName: filename,
+			Position:  i,
+			PublicID:  publicID,
+		}
+		if i < len(payload.AltTexts) {
+			imagePayload.AltText = payload.AltTexts[i]
 		}
 
 		publicIDs = append(publicIDs, publicID)
@@ -63,28 +85,76 @@ func (s *PostService) CreatePost(ctx context.Context, payload *models.PostPayloa
 	return nil
 }
 
-func (s *PostService) UpdatePost(ctx context.Context, post *postgresql.Post, payload *models.PostUpdatePayload) error {
-	if payload.Title != nil {
+// UpdatePost applies the changed fields, edits of someone else's post are
+// moderation and get audited with the fields that changed.
+func (s *PostService) UpdatePost(ctx context.Context, actor *postgresql.User, post *postgresql.Post, payload *models.PostUpdatePayload) error {
+	before, after := map[string]any{}, map[string]any{}
+
+	if payload.Title != nil && *payload.Title != post.Title {
+		before["title"], after["title"] = post.Title, *payload.Title
 		post.Title = *payload.Title
 	}
 
-	if payload.Content != nil {
+	if payload.Content != nil && *payload.Content != post.Content {
+		before["content"], after["content"] = post.Content, *payload.Content
 		post.Content = *payload.Content
 	}
 
 	if payload.Tags != nil {
-		post.Tags = *payload.Tags
+		tags, err := normalizeTags(*payload.Tags)
+		if err != nil {
+			return err
+		}
+
+		if !slices.Equal(tags, post.Tags) {
+			before["tags"], after["tags"] = post.Tags, tags
+			post.Tags = tags
+		}
+	}
+
+	if payload.Visibility != nil && *payload.Visibility != post.Visibility {
+		before["visibility"], after["visibility"] = post.Visibility, *payload.Visibility
+		post.Visibility = *payload.Visibility
 	}
 
-	if err := s.storage.Posts.UpdatePost(ctx, post); err != nil {
+	var event *postgresql.AuditEvent
+	if actor.ID != post.UserID {
+		var err error
+		event, err = newAuditEvent(actor, AuditPostUpdate, postgresql.AuditTargetPost, post.ID, before, after, payload.Client)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.storage.Posts.UpdatePost(ctx, post, actor.ID, event); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *PostService) DeletePost(ctx context.Context, postID int64) error {
-	if err := s.storage.Posts.DeletePost(ctx, postID); err != nil {
+// DeletePost removes the post, removing someone else's post is audited with a
+// copy of what was removed.
+func (s *PostService) DeletePost(ctx context.Context, actor *postgresql.User, post *postgresql.Post, client models.Client) error {
+	var event *postgresql.AuditEvent
+	if actor.ID != post.UserID {
+		var err error
+		event, err = newAuditEvent(actor, AuditPostDelete, postgresql.AuditTargetPost, post.ID,
+			map[string]any{
+				"user_id": post.UserID,
+				"title":   post.Title,
+				"content": post.Content,
+				"tags":    post.Tags,
+			},
+			nil,
+			client,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.storage.Posts.DeletePost(ctx, post.ID, actor.ID, event); err != nil {
 		return err
 	}
 
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+func (s *PostService) GetRevisions(ctx context.Context, postID int64) ([]models.RevisionResponse, error) {
+	revisions, err := s.storage.Revisions.GetByPost(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.RevisionResponse, 0, len(revisions))
+	for _, r := range revisions {
+		resp = append(resp, toRevisionResponse(&r))
+	}
+
+	return resp, nil
+}
+
+// GetRevisionDiff compares the revision with the version that replaced it,
+// the next revision or the post as it is now.
+func (s *PostService) GetRevisionDiff(ctx context.Context, post *postgresql.Post, revision int) (*models.RevisionDiffResponse, error) {
+	from, err := s.storage.Revisions.GetByRevision(ctx, post.ID, revision)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.RevisionDiffResponse{
+		Revision: toRevisionResponse(from),
+	}
+
+	title, content, tags := post.Title, post.Content, post.Tags
+	next, err := s.storage.Revisions.GetNext(ctx, post.ID, revision)
+	switch {
+	case err == nil:
+		title, content, tags = next.Title, next.Content, next.Tags
+		resp.NextRevision = &next.Revision
+	case !errors.Is(err, postgresql.ErrNotFound):
+		return nil, err
+	}
+
+	resp.TitleFrom, resp.TitleTo = from.Title, title
+	resp.TagsAdded, resp.TagsRemoved = diffTags(from.Tags, tags)
+	resp.Content = diffLines(from.Content, content)
+
+	return resp, nil
+}
+
+// RestoreRevision makes the revision the current version, the version it
+// replaces is kept as a new revision like any other edit.
+func (s *PostService) RestoreRevision(ctx context.Context, actor *postgresql.User, post *postgresql.Post, revision int, client models.Client) error {
+	r, err := s.storage.Revisions.GetByRevision(ctx, post.ID, revision)
+	if err != nil {
+		return err
+	}
+
+	// revisions from before tags were normalized keep them as they were typed
+	if r.Tags, err = normalizeTags(r.Tags); err != nil {
+		return err
+	}
+
+	if r.Title == post.Title && r.Content == post.Content && slices.Equal(r.Tags, post.Tags) {
+		return nil
+	}
+
+	var event *postgresql.AuditEvent
+	if actor.ID != post.UserID {
+		event, err = newAuditEvent(actor, AuditPostRestore, postgresql.AuditTargetPost, post.ID,
+			map[string]any{"title": post.Title, "content": post.Content, "tags": post.Tags},
+			map[string]any{"title": r.Title, "content": r.Content, "tags": r.Tags, "revision": r.Revision},
+			client,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	post.Title, post.Content, post.Tags = r.Title, r.Content, r.Tags
+	return s.storage.Posts.UpdatePost(ctx, post, actor.ID, event)
+}
+
+func toRevisionResponse(r *postgresql.PostRevision) models.RevisionResponse {
+	tags := r.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return models.RevisionResponse{
+		Revision:         r.Revision,
+		Title:            r.Title,
+		Content:          r.Content,
+		Tags:             tags,
+		EditedBy:         r.EditedBy,
+		EditedByUsername: r.EditedByUsername,
+		VersionCreatedAt: r.VersionCreatedAt,
+		ReplacedAt:       r.CreatedAt,
+	}
+}
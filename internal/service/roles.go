@@ -2,19 +2,129 @@ package service
 
 import (
 	"context"
+	"errors"
 
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 )
 
+var ErrUnknownPermission = errors.New("permission does not exist")
+
 type RoleService struct {
 	storage *postgresql.Storage
+	rbac    *rbac.Cache
 }
 
 func (s *RoleService) GetRole(ctx context.Context, roleName string) (*postgresql.Role, error) {
-	role, err := s.storage.Roles.GetByName(ctx, roleName)
+	role, err := s.rbac.GetRole(ctx, roleName)
 	if err != nil {
 		return nil, err
 	}
 
 	return role, nil
 }
+
+func (s *RoleService) HasPermission(ctx context.Context, user *postgresql.User, permission string) (bool, error) {
+	return s.rbac.HasPermission(ctx, user.Role.Name, permission)
+}
+
+func (s *RoleService) GetRoles(ctx context.Context) ([]models.RoleResponse, error) {
+	roles, err := s.rbac.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.RoleResponse, 0, len(roles))
+	for _, r := range roles {
+		permissions := r.Permissions
+		if permissions == nil {
+			permissions = []string{}
+		}
+
+		resp = append(resp, models.RoleResponse{
+			ID:          r.ID,
+			Name:        r.Name,
+			Level:       r.Level,
+			Description: r.Description,
+			Permissions: permissions,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *RoleService) GetPermissions(ctx context.Context) ([]models.PermissionResponse, error) {
+	permissions, err := s.storage.Roles.GetPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.PermissionResponse, 0, len(permissions))
+	for _, p := range permissions {
+		resp = append(resp, models.PermissionResponse{
+			Name:        p.Name,
+			Description: p.Description,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *RoleService) GrantPermission(ctx context.Context, actor *postgresql.User, roleName, permission string, client models.Client) error {
+	return s.changePermission(ctx, actor, roleName, permission, true, client)
+}
+
+func (s *RoleService) RevokePermission(ctx context.Context, actor *postgresql.User, roleName, permission string, client models.Client) error {
+	return s.changePermission(ctx, actor, roleName, permission, false, client)
+}
+
+func (s *RoleService) changePermission(ctx context.Context, actor *postgresql.User, roleName, permission string, grant bool, client models.Client) error {
+	role, err := s.storage.Roles.GetByName(ctx, roleName)
+	if err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return ErrUnknownRole
+		}
+		return err
+	}
+
+	// grants only move permissions the actor has, on roles that have nothing
+	// the actor lacks
+	holds, err := s.rbac.HasPermission(ctx, actor.Role.Name, permission)
+	if err != nil {
+		return err
+	}
+
+	covers, err := s.rbac.Covers(ctx, actor.Role.Name, role.Name)
+	if err != nil {
+		return err
+	}
+
+	if !holds || !covers {
+		return ErrOutranked
+	}
+
+	var before, after any
+	action, change := AuditRolePermissionRevoke, s.storage.Roles.RevokePermission
+	before = map[string]string{"permission": permission}
+	if grant {
+		action, change = AuditRolePermissionGrant, s.storage.Roles.GrantPermission
+		before, after = nil, before
+	}
+
+	event, err := newAuditEvent(actor, action, postgresql.AuditTargetRole, role.ID, before, after, client)
+	if err != nil {
+		return err
+	}
+
+	if err := change(ctx, role.ID, permission, event); err != nil {
+		if errors.Is(err, postgresql.ErrNotFound) {
+			return ErrUnknownPermission
+		}
+		return err
+	}
+
+	// other replicas reload on the database notification, this one reloads
+	// right away so the next request already sees the change
+	return s.rbac.Load(ctx)
+}
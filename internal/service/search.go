@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+var ErrInvalidCursor = errors.New("cursor is invalid")
+
+type SearchService struct {
+	storage *postgresql.Storage
+}
+
+// encodeCursor packs the rank and id of the last result, the rank is written
+// with float32 precision so it compares equal in the database again.
+func encodeCursor(rank float32, id int64) *string {
+	raw := strconv.FormatFloat(float64(rank), 'g', -1, 32) + ":" + strconv.FormatInt(id, 10)
+	cursor := base64.RawURLEncoding.EncodeToString([]byte(raw))
+	return &cursor
+}
+
+func decodeCursor(cursor string) (*postgresql.SearchCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	rankPart, idPart, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	rank, err := strconv.ParseFloat(rankPart, 32)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(idPart, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &postgresql.SearchCursor{Rank: float32(rank), ID: id}, nil
+}
+
+// highlight marks where text matches, for the trigram results the database
+// has no headline for. The text is user content, it is escaped so the only
+// markup in the result is the <mark> tags.
+func highlight(text, match string) string {
+	if match == "" {
+		return html.EscapeString(text)
+	}
+
+	pattern := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(match))
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringIndex(text, -1) {
+		b.WriteString(html.EscapeString(text[last:loc[0]]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[loc[0]:loc[1]]))
+		b.WriteString("</mark>")
+		last = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[last:]))
+
+	return b.String()
+}
+
+func escapeAll(texts []string) []string {
+	escaped := make([]string, 0, len(texts))
+	for _, text := range texts {
+		escaped = append(escaped, html.EscapeString(text))
+	}
+	return escaped
+}
+
+// markHeadline escapes a headline from the database and turns its match
+// markers into <mark> tags.
+func markHeadline(headline string) string {
+	return strings.NewReplacer(
+		postgresql.HighlightStart, "<mark>",
+		postgresql.HighlightStop, "</mark>",
+	).Replace(html.EscapeString(headline))
+}
+
+// Search runs the query against one type. Posts are searched as the viewer
+// sees them, so results never include a post they couldn't open, and users
+// on either side of a block with the viewer are left out.
+func (s *SearchService) Search(ctx context.Context, viewerID int64, payload *models.SearchPayload) (*models.SearchResponse, error) {
+	q := postgresql.SearchQuery{
+		Text:     strings.TrimSpace(payload.Query),
+		ViewerID: viewerID,
+		Limit:    payload.Limit,
+	}
+
+	if payload.Cursor != "" {
+		after, err := decodeCursor(payload.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.After = after
+	}
+
+	resp := &models.SearchResponse{Type: payload.Type}
+
+	switch payload.Type {
+	case "posts":
+		hits, err := s.storage.Search.Posts(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]models.SearchPostResponse, 0, len(hits))
+		for _, h := range hits {
+			results = append(results, models.SearchPostResponse{
+				ID:          h.ID,
+				UserID:      h.UserID,
+				Username:    html.EscapeString(h.Username),
+				Title:       markHeadline(h.Title),
+				Snippet:     markHeadline(h.Snippet),
+				Tags:        escapeAll(h.Tags),
+				PublishedAt: h.PublishedAt,
+				Rank:        h.Rank,
+			})
+		}
+		resp.Results = results
+
+		// a full page may have more behind it
+		if len(hits) == q.Limit {
+			resp.NextCursor = encodeCursor(hits[len(hits)-1].Rank, hits[len(hits)-1].ID)
+		}
+	case "users":
+		hits, err := s.storage.Search.Users(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]models.SearchUserResponse, 0, len(hits))
+		for _, h := range hits {
+			results = append(results, models.SearchUserResponse{
+				ID:       h.ID,
+				Username: highlight(h.Username, q.Text),
+				Fullname: highlight(h.Fullname, q.Text),
+				ImageURL: h.ImageURL,
+				Rank:     h.Rank,
+			})
+		}
+		resp.Results = results
+
+		if len(hits) == q.Limit {
+			resp.NextCursor = encodeCursor(hits[len(hits)-1].Rank, hits[len(hits)-1].ID)
+		}
+	case "tags":
+		q.Text = normalizeTag(q.Text)
+
+		hits, err := s.storage.Search.Tags(ctx, q)
+		if err != nil {
+			return nil, err
+		}
+
+		results := make([]models.SearchTagResponse, 0, len(hits))
+		for _, h := range hits {
+			results = append(results, models.SearchTagResponse{
+				Name:  highlight(h.Name, q.Text),
+				Posts: h.Posts,
+				Rank:  h.Rank,
+			})
+		}
+		resp.Results = results
+
+		if len(hits) == q.Limit {
+			resp.NextCursor = encodeCursor(hits[len(hits)-1].Rank, hits[len(hits)-1].ID)
+		}
+	default:
+		return nil, fmt.Errorf("unknown search type %q", payload.Type)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+func TestHighlightEscapesText(t *testing.T) {
+	got := highlight(`<script>alert("x")</script>`, "script")
+	want := `&lt;<mark>script</mark>&gt;alert(&#34;x&#34;)&lt;/<mark>script</mark>&gt;`
+	if got != want {
+		t.Fatalf("highlight() = %q, want %q", got, want)
+	}
+}
+
+func TestHighlightEscapesMatch(t *testing.T) {
+	got := highlight(`a <b> c`, "<b>")
+	want := `a <mark>&lt;b&gt;</mark> c`
+	if got != want {
+		t.Fatalf("highlight() = %q, want %q", got, want)
+	}
+}
+
+func TestMarkHeadlineEscapesTitle(t *testing.T) {
+	// what ts_headline returns for a post titled <script>alert(1)</script>
+	// searched for "alert"
+	headline := `<script>` + postgresql.HighlightStart + `alert` + postgresql.HighlightStop + `(1)</script>`
+
+	got := markHeadline(headline)
+	want := `&lt;script&gt;<mark>alert</mark>(1)&lt;/script&gt;`
+	if got != want {
+		t.Fatalf("markHeadline() = %q, want %q", got, want)
+	}
+}
@@ -5,41 +5,122 @@ import (
 	"database/sql"
 
 	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/mailer"
 	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/oidc"
+	"github.com/ArdiSasongko/SocialNetwork/internal/rbac"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/cldnary"
 	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
 )
 
 type Service struct {
 	Users interface {
-		GetProfileByID(context.Context, int64) (*models.UserResponse, error)
+		GetProfileByID(context.Context, int64, int64) (*models.UserResponse, error)
 		UpdateProfile(context.Context, *models.UpdateImagePayload) error
 		UpdateUser(context.Context, *postgresql.User, *models.UserUpdatePayload) error
 		FollowUser(context.Context, int64, int64) error
 		UnfollowUser(context.Context, int64, int64) error
+		BlockUser(context.Context, int64, int64) error
+		UnblockUser(context.Context, int64, int64) error
+		ChangePassword(context.Context, *postgresql.User, int64, *models.ChangePasswordPayload) error
 	}
 	Auth interface {
 		RegisterUser(context.Context, *models.UserPayload) error
-		LoginUser(context.Context, *models.LoginPayload) (string, error)
+		ActivateUser(context.Context, string) error
+		LoginUser(context.Context, *models.LoginPayload) (*models.LoginResponse, error)
+		VerifyMFA(context.Context, *models.MFAVerifyPayload) (*models.TokenResponse, error)
+		RefreshToken(context.Context, *models.RefreshPayload) (*models.TokenResponse, error)
+		Logout(context.Context, int64) error
+		ForgotPassword(context.Context, *models.ForgotPasswordPayload) error
+		ResetPassword(context.Context, *models.ResetPasswordPayload) error
+		StartOIDC(context.Context, int64) (*models.OIDCAuthorizationResponse, error)
+		OIDCCallback(context.Context, *models.OIDCCallbackPayload) (*models.OIDCCallbackResponse, error)
+		ConfirmOIDCLink(context.Context, int64, *models.OIDCLinkPayload) (*models.OIDCCallbackResponse, error)
+		GetIdentities(context.Context, int64) ([]models.IdentityResponse, error)
+		UnlinkIdentity(context.Context, int64, int64) error
+		GetSessions(context.Context, int64, int64) ([]models.SessionResponse, error)
+		RevokeSession(context.Context, int64, int64) error
+		RevokeOtherSessions(context.Context, int64, int64) error
+		RestoreAccount(context.Context, *models.RestoreAccountPayload) (*models.LoginResponse, error)
+	}
+	Accounts interface {
+		DeleteAccount(context.Context, *postgresql.User, *models.DeleteAccountPayload) (*models.DeleteAccountResponse, error)
+		ExportAccount(context.Context, *postgresql.User) (*models.AccountExport, error)
+		PurgeDeletedAccounts(context.Context) (int, error)
 	}
 	Post interface {
 		CreatePost(context.Context, *models.PostPayload) error
-		UpdatePost(context.Context, *postgresql.Post, *models.PostUpdatePayload) error
-		DeletePost(ctx context.Context, postID int64) error
+		UpdatePost(context.Context, *postgresql.User, *postgresql.Post, *models.PostUpdatePayload) error
+		DeletePost(context.Context, *postgresql.User, *postgresql.Post, models.Client) error
+		GetRevisions(context.Context, int64) ([]models.RevisionResponse, error)
+		GetRevisionDiff(context.Context, *postgresql.Post, int) (*models.RevisionDiffResponse, error)
+		RestoreRevision(context.Context, *postgresql.User, *postgresql.Post, int, models.Client) error
+		GetTrash(context.Context, int64) ([]models.TrashPostResponse, error)
+		RestorePost(context.Context, int64, int64) error
+		PurgeDeletedPosts(context.Context) (int, error)
+		GetDrafts(context.Context, int64) ([]models.DraftResponse, error)
+		GetDraft(context.Context, int64, int64) (*models.DraftResponse, error)
+		UpdateDraft(context.Context, int64, int64, *models.DraftUpdatePayload) (*models.DraftResponse, error)
+		DeleteDraft(context.Context, int64, int64) error
+		PublishScheduledPosts(context.Context) (int, error)
+		AddImages(context.Context, *postgresql.User, *postgresql.Post, *models.ImagesPayload) ([]models.ImageResponse, error)
+		RemoveImage(context.Context, *postgresql.User, *postgresql.Post, int64, models.Client) error
+		ReorderImages(context.Context, *postgresql.User, *postgresql.Post, *models.ImageOrderPayload) ([]models.ImageResponse, error)
+		UpdateImage(context.Context, *postgresql.User, *postgresql.Post, int64, *models.ImageUpdatePayload) error
+	}
+	MFA interface {
+		EnrollTOTP(context.Context, *postgresql.User) (*models.TOTPEnrollResponse, error)
+		ConfirmTOTP(context.Context, *postgresql.User, *models.MFACodePayload) (*models.RecoveryCodesResponse, error)
+		DisableTOTP(context.Context, *postgresql.User, *models.MFACodePayload) error
+	}
+	Tokens interface {
+		CreateToken(context.Context, int64, *models.PersonalTokenPayload) (*models.PersonalTokenResponse, error)
+		GetTokens(context.Context, int64) ([]models.PersonalTokenResponse, error)
+		GetToken(context.Context, int64, int64) (*models.PersonalTokenResponse, error)
+		UpdateToken(context.Context, int64, int64, *models.PersonalTokenUpdatePayload) (*models.PersonalTokenResponse, error)
+		DeleteToken(context.Context, int64, int64) error
 	}
 	Role interface {
 		GetRole(context.Context, string) (*postgresql.Role, error)
+		HasPermission(context.Context, *postgresql.User, string) (bool, error)
+		GetRoles(context.Context) ([]models.RoleResponse, error)
+		GetPermissions(context.Context) ([]models.PermissionResponse, error)
+		GrantPermission(context.Context, *postgresql.User, string, string, models.Client) error
+		RevokePermission(context.Context, *postgresql.User, string, string, models.Client) error
+	}
+	Admin interface {
+		GetLoginAttempts(context.Context, postgresql.LoginAttemptFilter) ([]models.LoginAttemptResponse, error)
+		GetAuditEvents(context.Context, postgresql.AuditFilter) (*models.AuditLogResponse, error)
+		GetUsers(context.Context, postgresql.UserFilter) ([]models.AdminUserResponse, error)
+		GetUser(context.Context, int64) (*models.AdminUserResponse, error)
+		ChangeRole(context.Context, *postgresql.User, int64, *models.ChangeRolePayload) (*models.AdminUserResponse, error)
+		SetActive(context.Context, *postgresql.User, int64, bool, models.Client) (*models.AdminUserResponse, error)
+		ForcePasswordReset(context.Context, *postgresql.User, int64, models.Client) (*models.ForcedResetResponse, error)
+	}
+	Tags interface {
+		GetPosts(context.Context, int64, string, postgresql.Pagination) (models.TagPostsResponse, error)
+		GetTrending(context.Context, int) ([]models.TrendingTagResponse, error)
+	}
+	Search interface {
+		Search(context.Context, int64, *models.SearchPayload) (*models.SearchResponse, error)
 	}
 	Feeds interface {
 		GetFeeds(context.Context, int64, postgresql.Pagination) (models.FeedsResponse, error)
-		GetFeed(context.Context, int64) (models.PostResponse, error)
-		LikePost(context.Context, *models.UserActivitiesPayload) error
-		DislikePost(context.Context, *models.UserActivitiesPayload) error
+		GetFeed(context.Context, *postgresql.Post) (models.PostResponse, error)
+		GetReactions(context.Context) ([]models.ReactionResponse, error)
+		ReactPost(context.Context, *models.UserActivitiesPayload) error
+		ReactComment(context.Context, *postgresql.Comment, *models.UserActivitiesPayload) error
 		CreateCommentPost(context.Context, *models.CommentPayload) error
+		CreateReply(context.Context, *postgresql.Comment, *models.CommentPayload) (*models.CommentResponse, error)
+		UpdateComment(context.Context, *postgresql.User, *postgresql.Comment, *models.CommentUpdatePayload) (*models.CommentResponse, error)
+		DeleteComment(context.Context, *postgresql.User, *postgresql.Comment, models.Client) error
+		SetCommentHidden(context.Context, *postgresql.User, *postgresql.Comment, bool, models.Client) error
+		GetCommentRevisions(context.Context, *postgresql.Comment) ([]models.CommentRevisionResponse, error)
+		GetComments(context.Context, int64, *int64, postgresql.CommentPage, string, int) (models.CommentsResponse, error)
 	}
 }
 
-func NewService(db *sql.DB, auth auth.Authenticator, cloudinary cldnary.ClientCloudinary) Service {
+func NewService(db *sql.DB, auth auth.Authenticator, cloudinary cldnary.ClientCloudinary, mailer mailer.Client, oidc *oidc.Provider, rbac *rbac.Cache) Service {
 	storage := postgresql.NewStorage(db)
 	return Service{
 		Users: &UserService{
@@ -51,13 +132,37 @@ func NewService(db *sql.DB, auth auth.Authenticator, cloudinary cldnary.ClientCl
 			storage:    &storage,
 			auth:       auth,
 			cloudinary: cloudinary,
+			mailer:     mailer,
+			oidc:       oidc,
 		},
 		Post: &PostService{
 			storage:    &storage,
 			cloudinary: cloudinary,
 		},
+		Accounts: &AccountService{
+			storage:    &storage,
+			cloudinary: cloudinary,
+		},
+		MFA: &MFAService{
+			storage: &storage,
+		},
+		Tokens: &TokenService{
+			storage: &storage,
+		},
 		Role: &RoleService{
 			storage: &storage,
+			rbac:    rbac,
+		},
+		Admin: &AdminService{
+			storage: &storage,
+			mailer:  mailer,
+			rbac:    rbac,
+		},
+		Tags: &TagService{
+			storage: &storage,
+		},
+		Search: &SearchService{
+			storage: &storage,
 		},
 		Feeds: &FeedService{
 			storage: &storage,
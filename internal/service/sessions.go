@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+)
+
+func (s *AuthService) GetSessions(ctx context.Context, userID, currentSessionID int64) ([]models.SessionResponse, error) {
+	sessions, err := s.storage.Sessions.GetActiveByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []models.SessionResponse{}
+	for _, session := range sessions {
+		resp = append(resp, models.SessionResponse{
+			ID:         session.ID,
+			Device:     describeDevice(session.UserAgent),
+			UserAgent:  session.UserAgent,
+			IPAddress:  session.IPAddress,
+			Current:    session.ID == currentSessionID,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			ExpiresAt:  session.ExpiresAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID int64) error {
+	return s.storage.Sessions.RevokeUserSession(ctx, userID, sessionID)
+}
+
+// RevokeOtherSessions logs the user out everywhere except the current device.
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID, currentSessionID int64) error {
+	return s.storage.Sessions.RevokeAllByUser(ctx, userID, currentSessionID)
+}
+
+var (
+	browsers = []struct{ token, name string }{
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+		{"curl/", "curl"},
+		{"PostmanRuntime/", "Postman"},
+	}
+	systems = []struct{ token, name string }{
+		{"Android", "Android"},
+		{"iPhone", "iOS"},
+		{"iPad", "iPadOS"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"CrOS", "ChromeOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// describeDevice turns a user agent into a short label like "Firefox on
+// Linux", order matters since most browsers also claim to be Safari.
+func describeDevice(userAgent string) string {
+	if userAgent == "" {
+		return "Unknown device"
+	}
+
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, sys := range systems {
+		if strings.Contains(userAgent, sys.token) {
+			system = sys.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "Unknown device"
+	}
+}
+
+func truncate(s string, limit int) string {
+	if len(s) > limit {
+		return s[:limit]
+	}
+
+	return s
+}
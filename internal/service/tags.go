@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+const (
+	maxTagLength   = 50
+	maxTagsPerPost = 10
+)
+
+var ErrInvalidTags = errors.New("invalid tags")
+
+// trendingWindow is how far back posts count towards trending tags.
+func trendingWindow() time.Duration {
+	return time.Hour * time.Duration(env.GetInt("TRENDING_WINDOW_HOURS", 168))
+}
+
+// trendingHalfLife is the age at which a use of a tag counts half.
+func trendingHalfLife() time.Duration {
+	return time.Hour * time.Duration(env.GetInt("TRENDING_HALF_LIFE_HOURS", 24))
+}
+
+// normalizeTag trims the tag, strips the leading # and case folds it so #Go,
+// go and " GO" are the same tag.
+func normalizeTag(tag string) string {
+	tag = strings.TrimSpace(tag)
+	tag = strings.TrimLeft(tag, "#")
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags normalizes every tag, dropping empty ones and duplicates
+// while keeping the order they were given in.
+func normalizeTags(tags []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, fmt.Errorf("%w: %q is longer than %d characters", ErrInvalidTags, tag, maxTagLength)
+		}
+
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > maxTagsPerPost {
+		return nil, fmt.Errorf("%w: a post can have at most %d tags", ErrInvalidTags, maxTagsPerPost)
+	}
+
+	return normalized, nil
+}
+
+type TagService struct {
+	storage *postgresql.Storage
+}
+
+func (s *TagService) GetPosts(ctx context.Context, viewerID int64, tag string, pf postgresql.Pagination) (models.TagPostsResponse, error) {
+	tag = normalizeTag(tag)
+
+	posts, err := s.storage.Tags.GetPosts(ctx, tag, viewerID, pf)
+	if err != nil {
+		return models.TagPostsResponse{}, err
+	}
+
+	resp := models.TagPostsResponse{
+		Tag:   tag,
+		Posts: []models.PostsResponse{},
+	}
+	for _, p := range posts {
+		resp.Posts = append(resp.Posts, models.PostsResponse{
+			Username: p.User.Username,
+			Title:    p.Title,
+			Content:  p.Content,
+			Tags:     p.Tags,
+			Images:   imageResponses(p.Images),
+			MetaData: postMetaData(p.CommentCount, p.Reactions),
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *TagService) GetTrending(ctx context.Context, limit int) ([]models.TrendingTagResponse, error) {
+	tags, err := s.storage.Tags.GetTrending(ctx, trendingWindow(), trendingHalfLife(), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.TrendingTagResponse, 0, len(tags))
+	for _, t := range tags {
+		resp = append(resp, models.TrendingTagResponse{
+			Name:  t.Name,
+			Uses:  t.Uses,
+			Score: t.Score,
+		})
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/auth"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+	"github.com/ArdiSasongko/SocialNetwork/internal/storage/postgresql"
+)
+
+var ErrDuplicateTokenName = errors.New("a token with this name already exists")
+
+type TokenService struct {
+	storage *postgresql.Storage
+}
+
+// CreateToken returns the plain token, it is the only time it can be seen.
+func (s *TokenService) CreateToken(ctx context.Context, userID int64, payload *models.PersonalTokenPayload) (*models.PersonalTokenResponse, error) {
+	plainToken, hash, err := auth.NewPersonalToken()
+	if err != nil {
+		return nil, err
+	}
+
+	token := postgresql.PersonalToken{
+		UserID:    userID,
+		Name:      payload.Name,
+		TokenHash: hash,
+		Scopes:    payload.Scopes,
+	}
+
+	if payload.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *payload.ExpiresInDays)
+		token.ExpiresAt = &expiresAt
+	}
+
+	if err := s.storage.Tokens.CreateToken(ctx, &token); err != nil {
+		if errors.Is(err, postgresql.ErrConflict) {
+			return nil, ErrDuplicateTokenName
+		}
+		return nil, err
+	}
+
+	resp := toTokenResponse(token)
+	resp.Token = plainToken
+	return &resp, nil
+}
+
+func (s *TokenService) GetTokens(ctx context.Context, userID int64) ([]models.PersonalTokenResponse, error) {
+	tokens, err := s.storage.Tokens.GetByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := []models.PersonalTokenResponse{}
+	for _, t := range tokens {
+		resp = append(resp, toTokenResponse(t))
+	}
+
+	return resp, nil
+}
+
+func (s *TokenService) GetToken(ctx context.Context, userID, tokenID int64) (*models.PersonalTokenResponse, error) {
+	token, err := s.storage.Tokens.GetByID(ctx, userID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toTokenResponse(*token)
+	return &resp, nil
+}
+
+func (s *TokenService) UpdateToken(ctx context.Context, userID, tokenID int64, payload *models.PersonalTokenUpdatePayload) (*models.PersonalTokenResponse, error) {
+	token, err := s.storage.Tokens.GetByID(ctx, userID, tokenID)
+	if err != nil {
+		return nil, err
+	}
+
+	if payload.Name != nil {
+		token.Name = *payload.Name
+	}
+
+	if payload.Scopes != nil {
+		token.Scopes = *payload.Scopes
+	}
+
+	if err := s.storage.Tokens.UpdateToken(ctx, token); err != nil {
+		if errors.Is(err, postgresql.ErrConflict) {
+			return nil, ErrDuplicateTokenName
+		}
+		return nil, err
+	}
+
+	resp := toTokenResponse(*token)
+	return &resp, nil
+}
+
+func (s *TokenService) DeleteToken(ctx context.Context, userID, tokenID int64) error {
+	return s.storage.Tokens.DeleteToken(ctx, userID, tokenID)
+}
+
+func toTokenResponse(t postgresql.PersonalToken) models.PersonalTokenResponse {
+	return models.PersonalTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Scopes:     t.Scopes,
+		ExpiresAt:  t.ExpiresAt,
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+		UpdatedAt:  t.UpdatedAt,
+	}
+}
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/ArdiSasongko/SocialNetwork/internal/env"
+	"github.com/ArdiSasongko/SocialNetwork/internal/models"
+)
+
+// postRetention is how long a deleted post stays in the trash.
+func postRetention() time.Duration {
+	return time.Hour * 24 * time.Duration(env.GetInt("POST_RETENTION_DAYS", 30))
+}
+
+func (s *PostService) GetTrash(ctx context.Context, userID int64) ([]models.TrashPostResponse, error) {
+	posts, err := s.storage.Posts.GetTrash(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]models.TrashPostResponse, 0, len(posts))
+	for _, p := range posts {
+		post := models.TrashPostResponse{
+			ID:         p.ID,
+			Title:      p.Title,
+			Content:    p.Content,
+			Tags:       p.Tags,
+			Images:     []string{},
+			IsEdited:   p.IsEdited,
+			CreatedAt:  p.CreatedAt,
+			DeletedAt:  *p.DeletedAt,
+			PurgeAfter: p.DeletedAt.Add(postRetention()),
+		}
+		for _, img := range p.Images {
+			post.Images = append(post.Images, img.ImageURL)
+		}
+		resp = append(resp, post)
+	}
+
+	return resp, nil
+}
+
+func (s *PostService) RestorePost(ctx context.Context, userID, postID int64) error {
+	return s.storage.Posts.RestorePost(ctx, userID, postID, time.Now().Add(-postRetention()))
+}
+
+// PurgeDeletedPosts hard deletes posts that have been in the trash longer
+// than the retention period and removes their images, it returns how many
+// posts were purged.
+func (s *PostService) PurgeDeletedPosts(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		purged, err := s.storage.Posts.PurgeDeleted(ctx, time.Now().Add(-postRetention()), purgeBatchSize)
+		if err != nil {
+			return total, err
+		}
+		total += len(purged)
+
+		for _, post := range purged {
+			deleteRemoteImages(ctx, s.cloudinary, post.Images)
+		}
+
+		if len(purged) < purgeBatchSize {
+			return total, nil
+		}
+	}
+}
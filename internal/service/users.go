@@ -17,7 +17,8 @@ type UserService struct {
 	cloudinary cldnary.ClientCloudinary
 }
 
-func (s *UserService) GetProfileByID(ctx context.Context, userID int64) (*models.UserResponse, error) {
+// GetProfileByID returns the profile of userID with the posts viewerID may read.
+func (s *UserService) GetProfileByID(ctx context.Context, viewerID, userID int64) (*models.UserResponse, error) {
 	var (
 		wg    sync.WaitGroup
 		user  *postgresql.User
@@ -41,7 +42,7 @@ func (s *UserService) GetProfileByID(ctx context.Context, userID int64) (*models
 	// fetch posts
 	go func() {
 		defer wg.Done()
-		p, err := s.getPostByUser(ctx, userID)
+		p, err := s.getPostByUser(ctx, viewerID, userID)
 		if err != nil {
 			errChan <- fmt.Errorf("get posts: %w", err)
 			return
@@ -123,13 +124,13 @@ func (s *UserService) UpdateUser(ctx context.Context, user *postgresql.User, pay
 	return nil
 }
 
-func (s *UserService) getPostByUser(ctx context.Context, userID int64) ([]models.PostsByUserResponse, error) {
+func (s *UserService) getPostByUser(ctx context.Context, viewerID, userID int64) ([]models.PostsByUserResponse, error) {
 	var (
 		post  models.PostsByUserResponse
 		posts []models.PostsByUserResponse
 	)
 
-	resp, err := s.storage.Posts.GetByUser(ctx, userID)
+	resp, err := s.storage.Posts.GetByUser(ctx, userID, viewerID)
 	if err != nil {
 		return []models.PostsByUserResponse{}, err
 	}
@@ -160,3 +161,31 @@ func (s *UserService) FollowUser(ctx context.Context, toFollow, userID int64) er
 func (s *UserService) UnfollowUser(ctx context.Context, toUnfollow, userID int64) error {
 	return s.storage.Follows.UnfollowUser(ctx, userID, toUnfollow)
 }
+
+// BlockUser hides the two users from each other in search and ends any
+// follow between them.
+func (s *UserService) BlockUser(ctx context.Context, toBlock, userID int64) error {
+	if toBlock == userID {
+		return fmt.Errorf("invalid data")
+	}
+
+	return s.storage.Blocks.BlockUser(ctx, userID, toBlock)
+}
+
+func (s *UserService) UnblockUser(ctx context.Context, toUnblock, userID int64) error {
+	return s.storage.Blocks.UnblockUser(ctx, userID, toUnblock)
+}
+
+// ChangePassword keeps the session that made the request alive and logs out
+// every other device.
+func (s *UserService) ChangePassword(ctx context.Context, user *postgresql.User, sessionID int64, payload *models.ChangePasswordPayload) error {
+	if err := user.Password.Compared(payload.CurrentPassword); err != nil {
+		return ErrInvalidPassword
+	}
+
+	if err := user.Password.Set(payload.NewPassword); err != nil {
+		return err
+	}
+
+	return s.storage.Passwords.ChangePassword(ctx, user.ID, user.Password.Hash, sessionID)
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 	"mime/multipart"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cloudinary/cloudinary-go/v2"
@@ -59,3 +61,30 @@ func (c *ClientCloudinary) DeleteImage(ctx context.Context, publicID string) err
 
 	return nil
 }
+
+// PublicIDFromURL recovers the public id from a delivery url like
+// https://res.cloudinary.com/<cloud>/image/upload/v123/<folder>/<name>.jpg,
+// rows stored before the public id was kept only have the url.
+func PublicIDFromURL(imageURL string) (string, bool) {
+	// images linked from elsewhere, e.g. an identity provider avatar
+	if !strings.Contains(imageURL, "res.cloudinary.com/") {
+		return "", false
+	}
+
+	_, path, ok := strings.Cut(imageURL, "/upload/")
+	if !ok || path == "" {
+		return "", false
+	}
+
+	if version, rest, found := strings.Cut(path, "/"); found && len(version) > 1 && version[0] == 'v' {
+		if _, err := strconv.ParseInt(version[1:], 10, 64); err == nil {
+			path = rest
+		}
+	}
+
+	if i := strings.LastIndex(path, "."); i > strings.LastIndex(path, "/") {
+		path = path[:i]
+	}
+
+	return path, path != ""
+}
@@ -0,0 +1,420 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type DeletedUser struct {
+	ID        int64
+	Email     string
+	Password  Password
+	DeletedAt time.Time
+}
+
+// PurgedUser lists what is left outside the database after an account was
+// purged, the remote images still have to be removed.
+type PurgedUser struct {
+	ID           int64
+	ProfileImage string
+	PostImages   []string
+}
+
+type Follow struct {
+	UserID    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	CreatedAt string `json:"created_at"`
+}
+
+type AccountExport struct {
+	Posts     []Post
+	Comments  []Comment
+	Reactions []Activities
+	Following []Follow
+	Followers []Follow
+}
+
+type AccountStore struct {
+	db *sql.DB
+}
+
+// Deactivate hides the account right away and logs it out everywhere, the
+// data stays until the purge job picks it up.
+func (s *AccountStore) Deactivate(ctx context.Context, userID int64, event *AuditEvent) (time.Time, error) {
+	query := `
+		UPDATE users
+		SET is_active = false, deleted_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL
+		RETURNING deleted_at
+	`
+
+	var deletedAt time.Time
+	return deletedAt, withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, userID).Scan(&deletedAt); err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		if err := revokeUserSessions(ctx, tx, userID, 0); err != nil {
+			return err
+		}
+
+		if err := deleteUserTokens(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+func (s *AccountStore) GetDeletedByEmail(ctx context.Context, email string) (*DeletedUser, error) {
+	query := `
+		SELECT id, email, password, deleted_at
+		FROM users
+		WHERE email = $1 AND deleted_at IS NOT NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	user := new(DeletedUser)
+	if err := s.db.QueryRowContext(ctx, query, email).Scan(
+		&user.ID,
+		&user.Email,
+		&user.Password.Hash,
+		&user.DeletedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+// Restore reactivates an account deleted after the given time, older ones
+// are past their grace period.
+func (s *AccountStore) Restore(ctx context.Context, userID int64, deletedAfter time.Time) error {
+	query := `
+		UPDATE users
+		SET is_active = true, deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND deleted_at > $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, deletedAfter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted hard deletes up to limit accounts deleted before the given
+// time, the foreign keys take posts, comments, reactions and follows with
+// them. Rows locked by another replica are skipped.
+func (s *AccountStore) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]PurgedUser, error) {
+	selectQuery := `
+		SELECT u.id, u.email, COALESCE(img.image_url, ''),
+			ARRAY(
+				SELECT ip.image_url
+				FROM images_post ip
+				JOIN posts p ON p.id = ip.post_id
+				WHERE p.user_id = u.id
+			)
+		FROM users u
+		LEFT JOIN image_profile img ON img.user_id = u.id
+		WHERE u.deleted_at < $1
+		ORDER BY u.deleted_at
+		LIMIT $2
+		FOR UPDATE OF u SKIP LOCKED
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	var purged []PurgedUser
+	return purged, withTx(s.db, ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectQuery, deletedBefore, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		var emails []string
+		for rows.Next() {
+			var (
+				user  PurgedUser
+				email string
+			)
+			if err := rows.Scan(
+				&user.ID,
+				&email,
+				&user.ProfileImage,
+				pq.Array(&user.PostImages),
+			); err != nil {
+				return err
+			}
+			purged = append(purged, user)
+			emails = append(emails, email)
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(purged) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(purged))
+		for _, user := range purged {
+			ids = append(ids, user.ID)
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM users WHERE id = ANY($1)`, pq.Array(ids)); err != nil {
+			return err
+		}
+
+		// login attempts keep the email and ip, they go too
+		if _, err := tx.ExecContext(ctx, `DELETE FROM login_attempts WHERE email = ANY($1)`, pq.Array(emails)); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+// Export collects everything tied to the user in one read only snapshot.
+func (s *AccountStore) Export(ctx context.Context, userID int64) (*AccountExport, error) {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	export := new(AccountExport)
+
+	if export.Posts, err = s.exportPosts(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	if export.Comments, err = s.exportComments(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	if export.Reactions, err = s.exportReactions(ctx, tx, userID); err != nil {
+		return nil, err
+	}
+
+	following := `
+		SELECT f.user_id, u.username, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = f.user_id
+		WHERE f.follower_id = $1
+		ORDER BY f.created_at
+	`
+	if export.Following, err = s.exportFollows(ctx, tx, following, userID); err != nil {
+		return nil, err
+	}
+
+	followers := `
+		SELECT f.follower_id, u.username, f.created_at
+		FROM follows f
+		JOIN users u ON u.id = f.follower_id
+		WHERE f.user_id = $1
+		ORDER BY f.created_at
+	`
+	if export.Followers, err = s.exportFollows(ctx, tx, followers, userID); err != nil {
+		return nil, err
+	}
+
+	return export, tx.Commit()
+}
+
+func (s *AccountStore) exportPosts(ctx context.Context, tx *sql.Tx, userID int64) ([]Post, error) {
+	query := `
+		SELECT p.id, p.user_id, p.title, p.content, p.tags, p.is_edited, p.created_at, p.updated_at,
+			COALESCE(ip.image_name, ''), COALESCE(ip.image_url, ''), COALESCE(ip.created_at, p.created_at)
+		FROM posts p
+		LEFT JOIN images_post ip ON ip.post_id = p.id
+		WHERE p.user_id = $1
+		ORDER BY p.created_at, p.id, ip.position
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []Post{}
+	for rows.Next() {
+		var (
+			post  Post
+			image ImagePost
+		)
+		if err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.Title,
+			&post.Content,
+			pq.Array(&post.Tags),
+			&post.IsEdited,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&image.ImageName,
+			&image.ImageURL,
+			&image.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		// one row per image, fold them back into their post
+		if n := len(posts); n == 0 || posts[n-1].ID != post.ID {
+			post.Images = []ImagePost{}
+			posts = append(posts, post)
+		}
+
+		if image.ImageURL != "" {
+			image.PostID = post.ID
+			last := &posts[len(posts)-1]
+			last.Images = append(last.Images, image)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (s *AccountStore) exportComments(ctx context.Context, tx *sql.Tx, userID int64) ([]Comment, error) {
+	query := `
+		SELECT id, user_id, post_id, content, is_edited, created_at, updated_at
+		FROM comments
+		WHERE user_id = $1
+		ORDER BY created_at, id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		var c Comment
+		if err := rows.Scan(
+			&c.ID,
+			&c.UserID,
+			&c.PostID,
+			&c.Content,
+			&c.IsEdited,
+			&c.CreatedAt,
+			&c.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		comments = append(comments, c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (s *AccountStore) exportReactions(ctx context.Context, tx *sql.Tx, userID int64) ([]Activities, error) {
+	query := `
+		SELECT ua.id, ua.user_id, COALESCE(ua.post_id, c.post_id), ua.comment_id, ua.reaction, ua.created_at, ua.updated_at
+		FROM user_activities ua
+		LEFT JOIN comments c ON c.id = ua.comment_id
+		WHERE ua.user_id = $1
+		ORDER BY ua.created_at, ua.id
+	`
+
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := []Activities{}
+	for rows.Next() {
+		var a Activities
+		if err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.PostID,
+			&a.CommentID,
+			&a.Reaction,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return reactions, nil
+}
+
+func (s *AccountStore) exportFollows(ctx context.Context, tx *sql.Tx, query string, userID int64) ([]Follow, error) {
+	rows, err := tx.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	follows := []Follow{}
+	for rows.Next() {
+		var f Follow
+		if err := rows.Scan(
+			&f.UserID,
+			&f.Username,
+			&f.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		follows = append(follows, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return follows, nil
+}
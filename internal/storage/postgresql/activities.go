@@ -3,113 +3,149 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 )
 
+var ErrUnknownReaction = errors.New("unknown reaction")
+
+const (
+	ReactionLike    = "like"
+	ReactionDislike = "dislike"
+)
+
 type Activities struct {
-	ID         int64  `json:"id"`
-	UserID     int64  `json:"user_id"`
-	PostID     int64  `json:"post_id"`
-	IsLiked    bool   `json:"is_liked"`
-	IsDisliked bool   `json:"is_disliked"`
-	CreatedAt  string `json:"created_at"`
-	UpdatedAt  string `json:"updated_at"`
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	PostID    int64  `json:"post_id"`
+	CommentID *int64 `json:"comment_id"`
+	Reaction  string `json:"reaction"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// Reaction is a kind of the catalogue, adding a row to the reactions table is
+// all it takes to offer a new one.
+type Reaction struct {
+	Kind     string `json:"kind"`
+	Emoji    string `json:"emoji"`
+	Position int    `json:"position"`
 }
 
 type UserActivities struct {
 	db *sql.DB
 }
 
-func (s *UserActivities) ToggleLikePost(ctx context.Context, us *Activities) error {
+func (s *UserActivities) GetReactions(ctx context.Context) ([]Reaction, error) {
 	query := `
-        INSERT INTO user_activities (user_id, post_id, is_liked, is_disliked)
-        VALUES ($1, $2, TRUE, FALSE)
-        ON CONFLICT (user_id, post_id)
-        DO UPDATE SET 
-            is_liked = CASE 
-                WHEN user_activities.is_liked = TRUE THEN FALSE 
-                ELSE TRUE 
-            END,
-            is_disliked = FALSE,
-            updated_at = CURRENT_TIMESTAMP
-        RETURNING is_liked
-    `
+		SELECT kind, emoji, position
+		FROM reactions
+		ORDER BY position, kind
+	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	var isLiked bool
-	err := s.db.QueryRowContext(ctx, query, us.UserID, us.PostID).Scan(&isLiked)
+	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
-		return fmt.Errorf("toggle like failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := []Reaction{}
+	for rows.Next() {
+		var r Reaction
+		if err := rows.Scan(&r.Kind, &r.Emoji, &r.Position); err != nil {
+			return nil, err
+		}
+		reactions = append(reactions, r)
 	}
 
-	us.IsLiked = isLiked
-	us.IsDisliked = false
-	return nil
+	return reactions, rows.Err()
 }
 
-func (s *UserActivities) ToggleDislikePost(ctx context.Context, us *Activities) error {
+// toggleReaction sets the reaction of the user on the target, replacing the
+// one they had. Giving the same reaction again takes it back, us.Reaction is
+// left empty then.
+func (s *UserActivities) toggleReaction(ctx context.Context, column string, targetID any, us *Activities) error {
 	query := `
-        INSERT INTO user_activities (user_id, post_id, is_liked, is_disliked)
-        VALUES ($1, $2, FALSE, TRUE)
-        ON CONFLICT (user_id, post_id)
-        DO UPDATE SET 
-            is_disliked = CASE 
-                WHEN user_activities.is_disliked = TRUE THEN FALSE 
-                ELSE TRUE 
-            END,
-            is_liked = FALSE,
-            updated_at = CURRENT_TIMESTAMP
-        RETURNING is_disliked
-    `
+		WITH removed AS (
+			DELETE FROM user_activities
+			WHERE user_id = $1 AND ` + column + ` = $2 AND reaction = $3
+			RETURNING id
+		)
+		INSERT INTO user_activities (user_id, ` + column + `, reaction)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (SELECT 1 FROM removed)
+		ON CONFLICT (user_id, ` + column + `)
+		DO UPDATE SET
+			reaction = EXCLUDED.reaction,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING reaction
+	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	var isDisliked bool
-	err := s.db.QueryRowContext(ctx, query, us.UserID, us.PostID).Scan(&isDisliked)
+	var reaction string
+	err := s.db.QueryRowContext(ctx, query, us.UserID, targetID, us.Reaction).Scan(&reaction)
 	if err != nil {
-		return fmt.Errorf("toggle dislike failed: %v", err)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			us.Reaction = ""
+			return nil
+		case err.Error() == `pq: insert or update on table "user_activities" violates foreign key constraint "fk_user_activities_reaction"`:
+			return ErrUnknownReaction
+		default:
+			return fmt.Errorf("toggle reaction failed: %v", err)
+		}
 	}
 
-	us.IsDisliked = isDisliked
-	us.IsLiked = false
+	us.Reaction = reaction
 	return nil
 }
 
-func (s *UserActivities) GetLikesByPost(ctx context.Context, postID int64) (int64, error) {
-	query := `
-        SELECT COUNT(*)
-        FROM user_activities
-        WHERE post_id = $1 AND is_liked = true
-    `
-
-	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
-	defer cancel()
-
-	var count int64
-	if err := s.db.QueryRowContext(ctx, query, postID).Scan(&count); err != nil {
-		return 0, err
-	}
+// ReactPost toggles us.Reaction on the post, a user has one reaction per
+// post.
+func (s *UserActivities) ReactPost(ctx context.Context, us *Activities) error {
+	return s.toggleReaction(ctx, "post_id", us.PostID, us)
+}
 
-	return count, nil
+// ReactComment works like ReactPost, a user has one reaction per comment.
+func (s *UserActivities) ReactComment(ctx context.Context, us *Activities) error {
+	return s.toggleReaction(ctx, "comment_id", us.CommentID, us)
 }
 
-func (s *UserActivities) GetDislikesByPost(ctx context.Context, postID int64) (int64, error) {
+// GetReactionsByPost counts the reactions of the post by kind, kinds nobody
+// used are left out.
+func (s *UserActivities) GetReactionsByPost(ctx context.Context, postID int64) (map[string]int64, error) {
 	query := `
-        SELECT COUNT(*)
-        FROM user_activities
-        WHERE post_id = $1 AND is_disliked = true
-    `
+		SELECT reaction, COUNT(*)
+		FROM user_activities
+		WHERE post_id = $1
+		GROUP BY reaction
+	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	var count int64
-	if err := s.db.QueryRowContext(ctx, query, postID).Scan(&count); err != nil {
-		return 0, err
+	rows, err := s.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]int64{}
+	for rows.Next() {
+		var (
+			reaction string
+			count    int64
+		)
+		if err := rows.Scan(&reaction, &count); err != nil {
+			return nil, err
+		}
+		counts[reaction] = count
 	}
 
-	return count, nil
+	return counts, rows.Err()
 }
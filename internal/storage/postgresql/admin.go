@@ -0,0 +1,200 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+type UserFilter struct {
+	Search string
+	Role   string
+	Active *bool
+	Pagination
+}
+
+type AdminStore struct {
+	db *sql.DB
+}
+
+const adminUserColumns = `
+	u.id, u.username, u.fullname, u.email, u.is_active, u.created_at, u.updated_at, u.deleted_at,
+	u.role, r.level, COALESCE(img.image_url, '')
+`
+
+func (s *AdminStore) GetUsers(ctx context.Context, filter UserFilter) ([]User, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if filter.Search != "" {
+		// escape the LIKE wildcards so they are matched literally
+		search := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(filter.Search)
+		args = append(args, "%"+search+"%")
+		conditions = append(conditions, fmt.Sprintf("(u.username ILIKE $%[1]d OR u.fullname ILIKE $%[1]d OR u.email ILIKE $%[1]d)", len(args)))
+	}
+
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("u.role = $%d", len(args)))
+	}
+
+	if filter.Active != nil {
+		args = append(args, *filter.Active)
+		conditions = append(conditions, fmt.Sprintf("u.is_active = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `
+		SELECT ` + adminUserColumns + `
+		FROM users u
+		JOIN roles r ON r.name = u.role
+		LEFT JOIN image_profile img ON img.user_id = u.id
+		` + where + `
+		ORDER BY u.id ` + filter.Sort + `
+		LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args))
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		if err := scanAdminUser(rows, &user); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// GetUser loads a user whatever the account status, Users.GetByID only sees
+// active accounts.
+func (s *AdminStore) GetUser(ctx context.Context, userID int64) (*User, error) {
+	query := `
+		SELECT ` + adminUserColumns + `
+		FROM users u
+		JOIN roles r ON r.name = u.role
+		LEFT JOIN image_profile img ON img.user_id = u.id
+		WHERE u.id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	user := new(User)
+	if err := scanAdminUser(s.db.QueryRowContext(ctx, query, userID), user); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return user, nil
+}
+
+func (s *AdminStore) UpdateRole(ctx context.Context, userID int64, role string, event *AuditEvent) error {
+	query := `
+		UPDATE users
+		SET role = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := execAffectingOne(ctx, tx, query, role, userID); err != nil {
+			return err
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+// SetActive turns an account on or off, deactivated users are logged out
+// everywhere and lose their personal access tokens.
+func (s *AdminStore) SetActive(ctx context.Context, userID int64, active bool, event *AuditEvent) error {
+	query := `
+		UPDATE users
+		SET is_active = $1, deleted_at = CASE WHEN $1 THEN NULL ELSE deleted_at END, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := execAffectingOne(ctx, tx, query, active, userID); err != nil {
+			return err
+		}
+
+		if !active {
+			if err := revokeUserSessions(ctx, tx, userID, 0); err != nil {
+				return err
+			}
+
+			if err := deleteUserTokens(ctx, tx, userID); err != nil {
+				return err
+			}
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+func execAffectingOne(ctx context.Context, tx *sql.Tx, query string, args ...any) error {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAdminUser(row rowScanner, user *User) error {
+	return row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Fullname,
+		&user.Email,
+		&user.IsActive,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+		&user.Role.Name,
+		&user.Role.Level,
+		&user.ImgURL.ImageURL,
+	)
+}
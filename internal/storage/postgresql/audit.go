@@ -0,0 +1,191 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	AuditTargetUser    = "user"
+	AuditTargetRole    = "role"
+	AuditTargetPost    = "post"
+	AuditTargetComment = "comment"
+)
+
+type AuditEvent struct {
+	ID         int64           `json:"id"`
+	ActorID    *int64          `json:"actor_id"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   int64           `json:"target_id"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IPAddress  string          `json:"ip_address"`
+	RequestID  string          `json:"request_id"`
+	CreatedAt  string          `json:"created_at"`
+}
+
+// AuditFilter pages through events newest first, BeforeID is the id of the
+// last event of the previous page.
+type AuditFilter struct {
+	ActorID    *int64
+	Action     string
+	TargetType string
+	TargetID   *int64
+	Since      *time.Time
+	Until      *time.Time
+	BeforeID   int64
+	Limit      int
+}
+
+type AuditStore struct {
+	db *sql.DB
+}
+
+// CreateEvent records an event that is not tied to a transaction of its own.
+func (s *AuditStore) CreateEvent(ctx context.Context, event *AuditEvent) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+func (s *AuditStore) GetEvents(ctx context.Context, filter AuditFilter) ([]AuditEvent, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if filter.ActorID != nil {
+		args = append(args, *filter.ActorID)
+		conditions = append(conditions, fmt.Sprintf("actor_id = $%d", len(args)))
+	}
+
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		conditions = append(conditions, fmt.Sprintf("action = $%d", len(args)))
+	}
+
+	if filter.TargetType != "" {
+		args = append(args, filter.TargetType)
+		conditions = append(conditions, fmt.Sprintf("target_type = $%d", len(args)))
+	}
+
+	if filter.TargetID != nil {
+		args = append(args, *filter.TargetID)
+		conditions = append(conditions, fmt.Sprintf("target_id = $%d", len(args)))
+	}
+
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	// ids only grow, so the id alone is a stable keyset
+	if filter.BeforeID > 0 {
+		args = append(args, filter.BeforeID)
+		conditions = append(conditions, fmt.Sprintf("id < $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit)
+	query := `
+		SELECT id, actor_id, action, target_type, target_id, before, after, ip_address, request_id, created_at
+		FROM audit_events
+		` + where + `
+		ORDER BY id DESC
+		LIMIT $` + fmt.Sprint(len(args))
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []AuditEvent{}
+	for rows.Next() {
+		var (
+			e             AuditEvent
+			before, after []byte
+		)
+		if err := rows.Scan(
+			&e.ID,
+			&e.ActorID,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&before,
+			&after,
+			&e.IPAddress,
+			&e.RequestID,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		e.Before, e.After = before, after
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// insertAuditEvent writes the event in the same transaction as the change it
+// describes, so a change is never committed without its record. A nil event
+// is for changes that don't need one, like owners editing their own posts.
+func insertAuditEvent(ctx context.Context, tx *sql.Tx, event *AuditEvent) error {
+	if event == nil {
+		return nil
+	}
+
+	query := `
+		INSERT INTO audit_events (actor_id, action, target_type, target_id, before, after, ip_address, request_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		event.ActorID,
+		event.Action,
+		event.TargetType,
+		event.TargetID,
+		nullJSON(event.Before),
+		nullJSON(event.After),
+		event.IPAddress,
+		event.RequestID,
+	).Scan(
+		&event.ID,
+		&event.CreatedAt,
+	)
+}
+
+func nullJSON(data json.RawMessage) any {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return []byte(data)
+}
@@ -0,0 +1,83 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrBlocked = errors.New("one of you has blocked the other")
+
+type BlockStore struct {
+	db *sql.DB
+}
+
+// notBlocked is the condition for the user in column and the user in
+// parameter $n not having blocked each other, either way round.
+func notBlocked(n int, column string) string {
+	return fmt.Sprintf(`NOT EXISTS (
+		SELECT 1 FROM user_blocks ub
+		WHERE (ub.blocker_id = $%[1]d AND ub.blocked_id = %[2]s)
+			OR (ub.blocker_id = %[2]s AND ub.blocked_id = $%[1]d)
+	)`, n, column)
+}
+
+// BlockUser also removes the follows between the two users, in both
+// directions.
+func (s *BlockStore) BlockUser(ctx context.Context, userID, toBlock int64) error {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			INSERT INTO user_blocks (blocker_id, blocked_id)
+			VALUES ($1, $2)
+		`
+
+		if _, err := tx.ExecContext(ctx, query, userID, toBlock); err != nil {
+			switch {
+			case err.Error() == `pq: duplicate key value violates unique constraint "pk_user_blocks"`:
+				return ErrConflict
+			case err.Error() == `pq: insert or update on table "user_blocks" violates foreign key constraint "fk_user_blocks_blocked_id"`:
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		query = `
+			DELETE FROM follows
+			WHERE (user_id = $1 AND follower_id = $2) OR (user_id = $2 AND follower_id = $1)
+		`
+
+		_, err := tx.ExecContext(ctx, query, userID, toBlock)
+		return err
+	})
+}
+
+func (s *BlockStore) UnblockUser(ctx context.Context, userID, toUnblock int64) error {
+	query := `
+		DELETE FROM user_blocks
+		WHERE blocker_id = $1 AND blocked_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, toUnblock)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -3,6 +3,10 @@ package postgresql
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"strconv"
+
+	"github.com/lib/pq"
 )
 
 type Comment struct {
@@ -14,75 +18,335 @@ type Comment struct {
 	CreatedAt    string `json:"created_at"`
 	UpdatedAt    string `json:"updated_at"`
 	CommentCount int64  `json:"comment_count"`
+	ParentID     *int64 `json:"parent_id"`
+	Depth        int    `json:"depth"`
+	ReplyCount   int64  `json:"reply_count"`
+	LikeCount    int64  `json:"like_count"`
+	Status       string `json:"status"`
+	Username     string `json:"username"`
+	ImageURL     string `json:"image_url"`
+	// Reactions counts the reactions by kind, LikeCount is kept apart as
+	// the top sort orders by it.
+	Reactions map[string]int64 `json:"reactions"`
 }
 
+const (
+	CommentVisible = "visible"
+	CommentHidden  = "hidden"
+	CommentDeleted = "deleted"
+)
+
+// commentStatus is the status of the comment aliased c, deleted and hidden
+// comments stay in their thread so the replies under them still show.
+const commentStatus = `CASE
+	WHEN c.deleted_at IS NOT NULL THEN 'deleted'
+	WHEN c.hidden_at IS NOT NULL THEN 'hidden'
+	ELSE 'visible'
+END`
+
 type CommentStore struct {
 	db *sql.DB
 }
 
 func (s *CommentStore) CreateComments(ctx context.Context, c *Comment) error {
 	query := `
-		INSERT INTO comments (user_id, post_id, content)
-		VALUES ($1, $2, $3)
+		INSERT INTO comments (user_id, post_id, content, parent_id, depth)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	if _, err := s.db.ExecContext(ctx, query, c.UserID, c.PostID, c.Content); err != nil {
+	if err := s.db.QueryRowContext(ctx, query, c.UserID, c.PostID, c.Content, c.ParentID, c.Depth).Scan(
+		&c.ID,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (s *CommentStore) GetCommentsByPost(ctx context.Context, postID int64) ([]Comment, error) {
+// GetByID returns a comment of the post.
+func (s *CommentStore) GetByID(ctx context.Context, postID, commentID int64) (*Comment, error) {
 	query := `
-        SELECT 
-            id, user_id, post_id, content, created_at, updated_at, is_edited
-        FROM comments
-        WHERE post_id = $1
-        ORDER BY created_at DESC
-    `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		` + commentJoins + `
+		WHERE c.id = $1 AND c.post_id = $2 AND c.deleted_at IS NULL
+	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	rows, err := s.db.QueryContext(ctx, query, postID)
+	c := new(Comment)
+	if err := scanComment(s.db.QueryRowContext(ctx, query, commentID, postID), c); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	reactions, err := s.getReactionsByComments(ctx, []int64{c.ID})
+	if err != nil {
+		return nil, err
+	}
+	c.Reactions = reactions[c.ID]
+
+	return c, nil
+}
+
+// CommentThread is a comment with the first of its direct replies.
+type CommentThread struct {
+	Comment
+	Replies []Comment
+}
+
+const (
+	CommentsNewest = "newest"
+	CommentsOldest = "oldest"
+	CommentsTop    = "top"
+)
+
+// CommentCursor is where the previous page ended, Key is the value sorted on
+// in its text form and ID breaks ties.
+type CommentCursor struct {
+	Key string
+	ID  int64
+}
+
+// CommentPage selects a page of comments in one of the sort orders.
+type CommentPage struct {
+	Sort  string
+	Limit int
+	After *CommentCursor
+}
+
+// commentColumns are the comment aliased c with its author, its status and
+// how many direct replies and likes it has.
+const commentColumns = `
+	c.id, c.user_id, c.post_id, c.parent_id, c.depth, c.content, c.is_edited, c.created_at, c.updated_at,
+	u.username, COALESCE(img.image_url, '') AS image_url,
+	` + commentStatus + ` AS status,
+	(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id) AS reply_count,
+	(SELECT COUNT(*) FROM user_activities ua WHERE ua.comment_id = c.id AND ua.reaction = 'like') AS like_count
+`
+
+const commentJoins = `
+	JOIN users u ON u.id = c.user_id
+	LEFT JOIN image_profile img ON img.user_id = c.user_id
+`
+
+func scanComment(row rowScanner, c *Comment) error {
+	return row.Scan(
+		&c.ID,
+		&c.UserID,
+		&c.PostID,
+		&c.ParentID,
+		&c.Depth,
+		&c.Content,
+		&c.IsEdited,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+		&c.Username,
+		&c.ImageURL,
+		&c.Status,
+		&c.ReplyCount,
+		&c.LikeCount,
+	)
+}
+
+// SortKey is the value of the comment a page sorted by sort continues from.
+func (c *Comment) SortKey(sort string) string {
+	if sort == CommentsTop {
+		return strconv.FormatInt(c.LikeCount, 10)
+	}
+	return c.CreatedAt
+}
+
+// commentOrder returns the column a sort orders by, its type and direction.
+func commentOrder(sort string) (column, cast, direction string) {
+	switch sort {
+	case CommentsOldest:
+		return "t.created_at", "timestamptz", "ASC"
+	case CommentsTop:
+		return "t.like_count", "bigint", "DESC"
+	default:
+		return "t.created_at", "timestamptz", "DESC"
+	}
+}
+
+// GetThreads returns a page of the comments under parentID, or of the top
+// level comments when it is nil, each with its reply count, its author and
+// its first replies oldest first. It takes three queries whatever the page
+// size.
+func (s *CommentStore) GetThreads(ctx context.Context, postID int64, parentID *int64, page CommentPage, replies int) ([]CommentThread, error) {
+	column, cast, direction := commentOrder(page.Sort)
+	compare := "<"
+	if direction == "ASC" {
+		compare = ">"
+	}
+
+	pageQuery := `
+		SELECT *
+		FROM (
+			SELECT ` + commentColumns + `
+			FROM comments c
+			` + commentJoins + `
+			WHERE c.post_id = $1 AND (($2::bigint IS NULL AND c.parent_id IS NULL) OR c.parent_id = $2)
+		) t
+		WHERE $3::text IS NULL OR (` + column + `, t.id) ` + compare + ` ($3::` + cast + `, $4::bigint)
+		ORDER BY ` + column + ` ` + direction + `, t.id ` + direction + `
+		LIMIT $5
+	`
+	repliesQuery := `
+		SELECT r.*
+		FROM unnest($1::bigint[]) AS p(id)
+		CROSS JOIN LATERAL (
+			SELECT ` + commentColumns + `
+			FROM comments c
+			` + commentJoins + `
+			WHERE c.parent_id = p.id
+			ORDER BY c.created_at, c.id
+			LIMIT $2
+		) r
+		ORDER BY r.created_at, r.id
+	`
+
+	var afterKey, afterID any
+	if page.After != nil {
+		afterKey, afterID = page.After.Key, page.After.ID
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, pageQuery, postID, parentID, afterKey, afterID, page.Limit)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var comments []Comment
+	threads := []CommentThread{}
+	ids := []int64{}
 	for rows.Next() {
-		var c Comment
-		if err := rows.Scan(
-			&c.ID,
-			&c.UserID,
-			&c.PostID,
-			&c.Content,
-			&c.CreatedAt,
-			&c.UpdatedAt,
-			&c.IsEdited,
-		); err != nil {
+		var t CommentThread
+		if err := scanComment(rows, &t.Comment); err != nil {
+			return nil, err
+		}
+		t.Replies = []Comment{}
+		threads = append(threads, t)
+		ids = append(ids, t.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return threads, nil
+	}
+
+	if replies > 0 {
+		if err := s.loadReplies(ctx, repliesQuery, threads, ids, replies); err != nil {
 			return nil, err
 		}
-		comments = append(comments, c)
 	}
 
-	if err = rows.Err(); err != nil {
+	for _, t := range threads {
+		for _, r := range t.Replies {
+			ids = append(ids, r.ID)
+		}
+	}
+
+	reactions, err := s.getReactionsByComments(ctx, ids)
+	if err != nil {
 		return nil, err
 	}
 
-	return comments, nil
+	for i := range threads {
+		threads[i].Reactions = reactions[threads[i].ID]
+		for j := range threads[i].Replies {
+			threads[i].Replies[j].Reactions = reactions[threads[i].Replies[j].ID]
+		}
+	}
+
+	return threads, nil
+}
+
+// loadReplies adds the first replies of every thread of the page.
+func (s *CommentStore) loadReplies(ctx context.Context, query string, threads []CommentThread, ids []int64, replies int) error {
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(ids), replies)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	index := make(map[int64]int, len(threads))
+	for i, t := range threads {
+		index[t.ID] = i
+	}
+
+	for rows.Next() {
+		var c Comment
+		if err := scanComment(rows, &c); err != nil {
+			return err
+		}
+		i := index[*c.ParentID]
+		threads[i].Replies = append(threads[i].Replies, c)
+	}
+
+	return rows.Err()
+}
+
+// getReactionsByComments counts the reactions of a page of comments by kind
+// in one query.
+func (s *CommentStore) getReactionsByComments(ctx context.Context, commentIDs []int64) (map[int64]map[string]int64, error) {
+	query := `
+		SELECT comment_id, reaction, COUNT(*)
+		FROM user_activities
+		WHERE comment_id = ANY($1)
+		GROUP BY comment_id, reaction
+	`
+
+	reactions := map[int64]map[string]int64{}
+	if len(commentIDs) == 0 {
+		return reactions, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(commentIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			commentID int64
+			reaction  string
+			count     int64
+		)
+		if err := rows.Scan(&commentID, &reaction, &count); err != nil {
+			return nil, err
+		}
+		if reactions[commentID] == nil {
+			reactions[commentID] = map[string]int64{}
+		}
+		reactions[commentID][reaction] = count
+	}
+
+	return reactions, rows.Err()
 }
 
 func (s *CommentStore) GetCommentCountByPost(ctx context.Context, postID int64) (int64, error) {
 	query := `
         SELECT COUNT(*)
         FROM comments
-        WHERE post_id = $1
+        WHERE post_id = $1 AND deleted_at IS NULL AND hidden_at IS NULL
     `
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
@@ -95,3 +359,153 @@ func (s *CommentStore) GetCommentCountByPost(ctx context.Context, postID int64)
 
 	return count, nil
 }
+
+type CommentRevision struct {
+	ID               int64  `json:"id"`
+	CommentID        int64  `json:"comment_id"`
+	Content          string `json:"content"`
+	EditedBy         *int64 `json:"edited_by"`
+	VersionCreatedAt string `json:"version_created_at"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// UpdateComment keeps the content it replaces as a revision.
+func (s *CommentStore) UpdateComment(ctx context.Context, c *Comment, editorID int64) error {
+	revisionQuery := `
+		WITH old AS (
+			SELECT id, content, updated_at
+			FROM comments
+			WHERE id = $1 AND deleted_at IS NULL
+			FOR UPDATE
+		)
+		INSERT INTO comment_revisions (comment_id, content, edited_by, version_created_at)
+		SELECT id, content, $2, updated_at
+		FROM old
+	`
+	query := `
+		UPDATE comments
+		SET content = $1, is_edited = true, updated_at = NOW()
+		WHERE id = $2 AND deleted_at IS NULL
+		RETURNING updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, revisionQuery, c.ID, editorID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		c.IsEdited = true
+		return tx.QueryRowContext(ctx, query, c.Content, c.ID).Scan(&c.UpdatedAt)
+	})
+}
+
+// DeleteComment leaves a tombstone so the replies keep their place in the
+// thread.
+func (s *CommentStore) DeleteComment(ctx context.Context, commentID, deletedBy int64, event *AuditEvent) error {
+	query := `
+		UPDATE comments
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, commentID, deletedBy)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+// SetHidden hides the comment for hiddenBy, or shows it again when nil.
+func (s *CommentStore) SetHidden(ctx context.Context, commentID int64, hiddenBy *int64, event *AuditEvent) error {
+	query := `
+		UPDATE comments
+		SET hidden_at = CASE WHEN $2::int IS NULL THEN NULL ELSE NOW() END, hidden_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, commentID, hiddenBy)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+// GetRevisions returns the earlier versions of the comment, newest first.
+func (s *CommentStore) GetRevisions(ctx context.Context, commentID int64) ([]CommentRevision, error) {
+	query := `
+		SELECT id, comment_id, content, edited_by, version_created_at, created_at
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, commentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []CommentRevision{}
+	for rows.Next() {
+		var r CommentRevision
+		if err := rows.Scan(
+			&r.ID,
+			&r.CommentID,
+			&r.Content,
+			&r.EditedBy,
+			&r.VersionCreatedAt,
+			&r.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+
+	return revisions, rows.Err()
+}
@@ -0,0 +1,195 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+const draftColumns = `
+	p.id, p.user_id, p.title, p.content, p.tags, p.status, p.publish_at, p.visibility, p.created_at, p.updated_at,
+	ARRAY(
+		SELECT ip.image_url
+		FROM images_post ip
+		WHERE ip.post_id = p.id
+		ORDER BY ip.position, ip.id
+	)
+`
+
+// GetDrafts returns the user's drafts and scheduled posts, most recently
+// edited first.
+func (s *PostStore) GetDrafts(ctx context.Context, userID int64) ([]Post, error) {
+	query := `
+		SELECT ` + draftColumns + `
+		FROM posts p
+		WHERE p.user_id = $1 AND p.status <> 'published' AND p.deleted_at IS NULL
+		ORDER BY p.updated_at DESC, p.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []Post{}
+	for rows.Next() {
+		var post Post
+		if err := scanDraft(rows, &post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (s *PostStore) GetDraft(ctx context.Context, userID, postID int64) (*Post, error) {
+	query := `
+		SELECT ` + draftColumns + `
+		FROM posts p
+		WHERE p.id = $1 AND p.user_id = $2 AND p.status <> 'published' AND p.deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	post := new(Post)
+	if err := scanDraft(s.db.QueryRowContext(ctx, query, postID, userID), post); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return post, nil
+}
+
+// UpdateDraft saves a draft or scheduled post. Drafts are not revisioned, and
+// once the scheduler got to it first the post is no longer a draft.
+func (s *PostStore) UpdateDraft(ctx context.Context, p *Post) error {
+	query := `
+		UPDATE posts
+		SET title = $1, content = $2, tags = $3, status = $4, publish_at = $5,
+			published_at = CASE WHEN $6 THEN NOW() END, visibility = $7, updated_at = NOW()
+		WHERE id = $8 AND user_id = $9 AND status <> 'published' AND deleted_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(
+			ctx,
+			query,
+			p.Title,
+			p.Content,
+			pq.Array(p.Tags),
+			p.Status,
+			p.PublishAt,
+			p.Status == PostPublished,
+			p.Visibility,
+			p.ID,
+			p.UserID,
+		)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		if err := syncMentions(ctx, tx, p.ID, p.Content); err != nil {
+			return err
+		}
+
+		return syncTags(ctx, tx, p.ID, p.Tags)
+	})
+}
+
+// PublishDue publishes up to limit scheduled posts whose time has come and
+// returns their ids. Rows locked by another replica are skipped, and the
+// status check keeps a post from being published twice.
+func (s *PostStore) PublishDue(ctx context.Context, limit int) ([]int64, error) {
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM posts
+			WHERE status = 'scheduled' AND publish_at <= NOW() AND deleted_at IS NULL
+			ORDER BY publish_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE posts p
+		SET status = 'published', published_at = p.publish_at, updated_at = NOW()
+		FROM due
+		WHERE p.id = due.id
+		RETURNING p.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int64{}
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+func scanDraft(row rowScanner, post *Post) error {
+	var images []string
+	if err := row.Scan(
+		&post.ID,
+		&post.UserID,
+		&post.Title,
+		&post.Content,
+		pq.Array(&post.Tags),
+		&post.Status,
+		&post.PublishAt,
+		&post.Visibility,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		pq.Array(&images),
+	); err != nil {
+		return err
+	}
+
+	for _, url := range images {
+		post.Images = append(post.Images, ImagePost{PostID: post.ID, ImageURL: url})
+	}
+
+	return nil
+}
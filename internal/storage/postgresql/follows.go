@@ -10,16 +10,20 @@ type FollowStore struct {
 	db *sql.DB
 }
 
+// FollowUser returns ErrBlocked when either user has blocked the other, a
+// block ends the follows between them and keeps them ended.
 func (s *FollowStore) FollowUser(ctx context.Context, userID, toFollow int64) error {
 	query := `
 		INSERT INTO follows (user_id, follower_id)
-		VALUES ($1, $2)
+		SELECT $1, $2
+		WHERE ` + notBlocked(2, "$1") + `
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	if _, err := s.db.ExecContext(ctx, query, toFollow, userID); err != nil {
+	res, err := s.db.ExecContext(ctx, query, toFollow, userID)
+	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
 			return ErrNotFound
@@ -30,6 +34,15 @@ func (s *FollowStore) FollowUser(ctx context.Context, userID, toFollow int64) er
 		}
 	}
 
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrBlocked
+	}
+
 	return nil
 }
 
@@ -0,0 +1,223 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Identity struct {
+	ID        int64  `json:"id"`
+	UserID    int64  `json:"user_id"`
+	Provider  string `json:"provider"`
+	Subject   string `json:"subject"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+type OIDCState struct {
+	State        string
+	CodeVerifier string
+	Nonce        string
+	LinkUserID   int64
+	ExpiresAt    time.Time
+}
+
+type IdentityStore struct {
+	db *sql.DB
+}
+
+func (s *IdentityStore) GetByProviderSubject(ctx context.Context, provider, subject string) (*Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	identity := new(Identity)
+	if err := s.db.QueryRowContext(ctx, query, provider, subject).Scan(
+		&identity.ID,
+		&identity.UserID,
+		&identity.Provider,
+		&identity.Subject,
+		&identity.Email,
+		&identity.CreatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return identity, nil
+}
+
+func (s *IdentityStore) GetByUser(ctx context.Context, userID int64) ([]Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, COALESCE(email, ''), created_at
+		FROM user_identities
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []Identity{}
+	for rows.Next() {
+		var i Identity
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Provider,
+			&i.Subject,
+			&i.Email,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		identities = append(identities, i)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return identities, nil
+}
+
+func (s *IdentityStore) CreateIdentity(ctx context.Context, identity *Identity) error {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return insertIdentity(ctx, tx, identity)
+	})
+}
+
+func (s *IdentityStore) DeleteIdentity(ctx context.Context, userID, identityID int64) error {
+	query := `
+		DELETE FROM user_identities
+		WHERE id = $1 AND user_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, identityID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *IdentityStore) SaveState(ctx context.Context, state *OIDCState) error {
+	query := `
+		INSERT INTO oidc_states (state, code_verifier, nonce, link_user_id, expires_at)
+		VALUES ($1, $2, $3, NULLIF($4, 0), $5)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	// expired states of abandoned logins are cleaned up on the way
+	if _, err := s.db.ExecContext(ctx, `DELETE FROM oidc_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+
+	if _, err := s.db.ExecContext(
+		ctx,
+		query,
+		state.State,
+		state.CodeVerifier,
+		state.Nonce,
+		state.LinkUserID,
+		state.ExpiresAt,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ConsumeState deletes the state so a callback can't be replayed.
+func (s *IdentityStore) ConsumeState(ctx context.Context, state string) (*OIDCState, error) {
+	query := `
+		DELETE FROM oidc_states
+		WHERE state = $1 AND expires_at > NOW()
+		RETURNING state, code_verifier, nonce, COALESCE(link_user_id, 0), expires_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	result := new(OIDCState)
+	if err := s.db.QueryRowContext(ctx, query, state).Scan(
+		&result.State,
+		&result.CodeVerifier,
+		&result.Nonce,
+		&result.LinkUserID,
+		&result.ExpiresAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func insertIdentity(ctx context.Context, tx *sql.Tx, identity *Identity) error {
+	query := `
+		INSERT INTO user_identities (user_id, provider, subject, email)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at
+	`
+
+	if err := tx.QueryRowContext(
+		ctx,
+		query,
+		identity.UserID,
+		identity.Provider,
+		identity.Subject,
+		identity.Email,
+	).Scan(
+		&identity.ID,
+		&identity.CreatedAt,
+	); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_provider_subject"`,
+			err.Error() == `pq: duplicate key value violates unique constraint "unique_user_provider"`:
+			return ErrConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
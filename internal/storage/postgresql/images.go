@@ -0,0 +1,225 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+var ErrInvalidImageOrder = errors.New("image order must list every image of the post exactly once")
+
+// lockPost serialises changes to the images of a post so positions stay
+// unique while two requests append or reorder at the same time.
+func lockPost(ctx context.Context, tx *sql.Tx, postID int64) error {
+	query := `SELECT id FROM posts WHERE id = $1 FOR UPDATE`
+
+	var id int64
+	if err := tx.QueryRowContext(ctx, query, postID).Scan(&id); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getImagesByPosts loads the images of a page of posts in one query, for the
+// stores that list posts.
+func getImagesByPosts(ctx context.Context, db *sql.DB, postIDs []int64) (map[int64][]ImagePost, error) {
+	query := `
+		SELECT id, image_name, image_url, post_id, position, alt_text, created_at
+		FROM images_post
+		WHERE post_id = ANY($1)
+		ORDER BY post_id, position, id
+	`
+
+	images := map[int64][]ImagePost{}
+	if len(postIDs) == 0 {
+		return images, nil
+	}
+
+	rows, err := db.QueryContext(ctx, query, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var image ImagePost
+		if err := rows.Scan(
+			&image.ID,
+			&image.ImageName,
+			&image.ImageURL,
+			&image.PostID,
+			&image.Position,
+			&image.AltText,
+			&image.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		images[image.PostID] = append(images[image.PostID], image)
+	}
+
+	return images, rows.Err()
+}
+
+func (s *PostStore) GetImages(ctx context.Context, postID int64) ([]ImagePost, error) {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	var images []ImagePost
+	err := withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var err error
+		images, err = s.getImageByID(ctx, tx, postID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+// AddImages appends the images after the ones the post already has.
+func (s *PostStore) AddImages(ctx context.Context, postID int64, images []ImagePost, event *AuditEvent) error {
+	query := `SELECT COALESCE(MAX(position) + 1, 0) FROM images_post WHERE post_id = $1`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := lockPost(ctx, tx, postID); err != nil {
+			return err
+		}
+
+		var next int
+		if err := tx.QueryRowContext(ctx, query, postID).Scan(&next); err != nil {
+			return err
+		}
+
+		for i, image := range images {
+			image.Position = next + i
+			if err := s.insertImage(ctx, tx, postID, image); err != nil {
+				return err
+			}
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+// RemoveImage deletes the image row and returns it so the caller can clean up
+// the remote file.
+func (s *PostStore) RemoveImage(ctx context.Context, postID, imageID int64, event *AuditEvent) (*ImagePost, error) {
+	query := `
+		DELETE FROM images_post
+		WHERE id = $1 AND post_id = $2
+		RETURNING id, image_name, image_url, post_id, position, COALESCE(public_id, ''), alt_text
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	image := new(ImagePost)
+	return image, withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, query, imageID, postID).Scan(
+			&image.ID,
+			&image.ImageName,
+			&image.ImageURL,
+			&image.PostID,
+			&image.Position,
+			&image.PublicID,
+			&image.AltText,
+		); err != nil {
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				return ErrNotFound
+			default:
+				return err
+			}
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+// ReorderImages sets the positions from the order of imageIDs, which has to
+// hold every image of the post once.
+func (s *PostStore) ReorderImages(ctx context.Context, postID int64, imageIDs []int64, event *AuditEvent) error {
+	countQuery := `SELECT COUNT(*) FROM images_post WHERE post_id = $1`
+	query := `
+		UPDATE images_post ip
+		SET position = o.ord - 1, updated_at = NOW()
+		FROM unnest($2::bigint[]) WITH ORDINALITY AS o(id, ord)
+		WHERE ip.id = o.id AND ip.post_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := lockPost(ctx, tx, postID); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.QueryRowContext(ctx, countQuery, postID).Scan(&count); err != nil {
+			return err
+		}
+
+		if count != int64(len(imageIDs)) {
+			return ErrInvalidImageOrder
+		}
+
+		res, err := tx.ExecContext(ctx, query, postID, pq.Array(imageIDs))
+		if err != nil {
+			return err
+		}
+
+		// duplicated or foreign ids update fewer rows than were listed
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows != count {
+			return ErrInvalidImageOrder
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+func (s *PostStore) UpdateImageAltText(ctx context.Context, postID, imageID int64, altText string, event *AuditEvent) error {
+	query := `
+		UPDATE images_post
+		SET alt_text = $1, updated_at = NOW()
+		WHERE id = $2 AND post_id = $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, altText, imageID, postID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
@@ -0,0 +1,175 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	LoginSuccess = "success"
+	LoginFailed  = "failed"
+	LoginLocked  = "locked"
+)
+
+type LoginAttempt struct {
+	ID        int64  `json:"id"`
+	UserID    *int64 `json:"user_id"`
+	Email     string `json:"email"`
+	IPAddress string `json:"ip_address"`
+	UserAgent string `json:"user_agent"`
+	Result    string `json:"result"`
+	CreatedAt string `json:"created_at"`
+}
+
+type LoginAttemptFilter struct {
+	Email     string
+	IPAddress string
+	Result    string
+	Pagination
+}
+
+type LoginAttemptStore struct {
+	db *sql.DB
+}
+
+func (s *LoginAttemptStore) CreateAttempt(ctx context.Context, attempt *LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (user_id, email, ip_address, user_agent, result)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		query,
+		attempt.UserID,
+		attempt.Email,
+		attempt.IPAddress,
+		attempt.UserAgent,
+		attempt.Result,
+	).Scan(
+		&attempt.ID,
+		&attempt.CreatedAt,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// CountFailuresByEmail counts failed attempts since the given time, a
+// successful login resets the counter.
+func (s *LoginAttemptStore) CountFailuresByEmail(ctx context.Context, email string, since time.Time) (int, time.Time, error) {
+	query := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE email = $1 AND result = 'failed' AND created_at >= GREATEST($2, (
+			SELECT COALESCE(MAX(created_at), $2)
+			FROM login_attempts
+			WHERE email = $1 AND result = 'success'
+		))
+	`
+
+	return s.countFailures(ctx, query, email, since)
+}
+
+// CountFailuresByIP is not reset by a success, one address guessing many
+// accounts should still slow down.
+func (s *LoginAttemptStore) CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int, time.Time, error) {
+	query := `
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE ip_address = $1 AND result = 'failed' AND created_at >= $2
+	`
+
+	return s.countFailures(ctx, query, ip, since)
+}
+
+func (s *LoginAttemptStore) countFailures(ctx context.Context, query, key string, since time.Time) (int, time.Time, error) {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	var (
+		count  int
+		lastAt sql.NullTime
+	)
+
+	if err := s.db.QueryRowContext(ctx, query, key, since).Scan(&count, &lastAt); err != nil {
+		return 0, time.Time{}, err
+	}
+
+	return count, lastAt.Time, nil
+}
+
+func (s *LoginAttemptStore) GetAttempts(ctx context.Context, filter LoginAttemptFilter) ([]LoginAttempt, error) {
+	var (
+		conditions []string
+		args       []any
+	)
+
+	if filter.Email != "" {
+		args = append(args, strings.ToLower(filter.Email))
+		conditions = append(conditions, fmt.Sprintf("email = $%d", len(args)))
+	}
+
+	if filter.IPAddress != "" {
+		args = append(args, filter.IPAddress)
+		conditions = append(conditions, fmt.Sprintf("ip_address = $%d", len(args)))
+	}
+
+	if filter.Result != "" {
+		args = append(args, filter.Result)
+		conditions = append(conditions, fmt.Sprintf("result = $%d", len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	args = append(args, filter.Limit, filter.Offset)
+	query := `
+		SELECT id, user_id, email, ip_address, user_agent, result, created_at
+		FROM login_attempts
+		` + where + `
+		ORDER BY created_at ` + filter.Sort + `, id ` + filter.Sort + `
+		LIMIT $` + fmt.Sprint(len(args)-1) + ` OFFSET $` + fmt.Sprint(len(args))
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attempts := []LoginAttempt{}
+	for rows.Next() {
+		var a LoginAttempt
+		if err := rows.Scan(
+			&a.ID,
+			&a.UserID,
+			&a.Email,
+			&a.IPAddress,
+			&a.UserAgent,
+			&a.Result,
+			&a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		attempts = append(attempts, a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
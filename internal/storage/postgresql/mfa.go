@@ -0,0 +1,217 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type MFA struct {
+	UserID       int64      `json:"user_id"`
+	Secret       string     `json:"-"`
+	EnabledAt    *time.Time `json:"enabled_at"`
+	LastUsedStep int64      `json:"-"`
+	CreatedAt    string     `json:"created_at"`
+	UpdatedAt    string     `json:"updated_at"`
+}
+
+func (m *MFA) IsEnabled() bool {
+	return m != nil && m.EnabledAt != nil
+}
+
+type MFAStore struct {
+	db *sql.DB
+}
+
+func (s *MFAStore) GetByUser(ctx context.Context, userID int64) (*MFA, error) {
+	query := `
+		SELECT user_id, secret, enabled_at, last_used_step, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	mfa := new(MFA)
+	if err := s.db.QueryRowContext(ctx, query, userID).Scan(
+		&mfa.UserID,
+		&mfa.Secret,
+		&mfa.EnabledAt,
+		&mfa.LastUsedStep,
+		&mfa.CreatedAt,
+		&mfa.UpdatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return mfa, nil
+}
+
+// SetPendingSecret stores a new secret awaiting confirmation. It refuses to
+// touch a secret that is already enabled.
+func (s *MFAStore) SetPendingSecret(ctx context.Context, userID int64, secret string) error {
+	query := `
+		INSERT INTO user_mfa (user_id, secret)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id)
+		DO UPDATE SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = NOW()
+		WHERE user_mfa.enabled_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrConflict
+	}
+
+	return nil
+}
+
+func (s *MFAStore) Enable(ctx context.Context, userID, step int64, codeHashes [][]byte) error {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		query := `
+			UPDATE user_mfa
+			SET enabled_at = NOW(), last_used_step = $1, updated_at = NOW()
+			WHERE user_id = $2 AND enabled_at IS NULL
+		`
+
+		res, err := tx.ExecContext(ctx, query, step, userID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		return s.replaceRecoveryCodes(ctx, tx, userID, codeHashes)
+	})
+}
+
+func (s *MFAStore) Disable(ctx context.Context, userID int64) error {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+
+		res, err := tx.ExecContext(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		return nil
+	})
+}
+
+// UseStep records the totp time step as consumed, a step that is not newer
+// than the last accepted one is rejected with ErrTokenReused.
+func (s *MFAStore) UseStep(ctx context.Context, userID, step int64) error {
+	query := `
+		UPDATE user_mfa
+		SET last_used_step = $1, updated_at = NOW()
+		WHERE user_id = $2 AND last_used_step < $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, step, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrTokenReused
+	}
+
+	return nil
+}
+
+func (s *MFAStore) UseRecoveryCode(ctx context.Context, userID int64, codeHash []byte) error {
+	query := `
+		UPDATE mfa_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID, codeHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *MFAStore) replaceRecoveryCodes(ctx context.Context, tx *sql.Tx, userID int64, codeHashes [][]byte) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO mfa_recovery_codes (user_id, code_hash)
+		VALUES ($1, $2)
+	`
+
+	for _, hash := range codeHashes {
+		if _, err := tx.ExecContext(ctx, query, userID, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
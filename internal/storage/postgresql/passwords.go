@@ -0,0 +1,168 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type PasswordStore struct {
+	db *sql.DB
+}
+
+func (s *PasswordStore) ChangePassword(ctx context.Context, userID int64, hash []byte, keepSessionID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.updatePassword(ctx, tx, userID, hash); err != nil {
+			return err
+		}
+
+		return revokeUserSessions(ctx, tx, userID, keepSessionID)
+	})
+}
+
+func (s *PasswordStore) CreateReset(ctx context.Context, userID int64, token []byte, exp time.Duration) error {
+	query := `
+		INSERT INTO password_resets (token_hash, user_id, expiry)
+		VALUES ($1, $2, $3)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, query, token, userID, time.Now().Add(exp)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ForcePasswordReset replaces the password with an unusable one, logs the
+// user out everywhere and leaves a fresh reset token as the only way back in.
+func (s *PasswordStore) ForcePasswordReset(ctx context.Context, userID int64, hash []byte, token []byte, exp time.Duration, event *AuditEvent) error {
+	query := `
+		INSERT INTO password_resets (token_hash, user_id, expiry)
+		VALUES ($1, $2, $3)
+	`
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := s.updatePassword(ctx, tx, userID, hash); err != nil {
+			return err
+		}
+
+		if err := s.invalidateResets(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		if err := revokeUserSessions(ctx, tx, userID, 0); err != nil {
+			return err
+		}
+
+		if err := deleteUserTokens(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+		defer cancel()
+
+		if _, err := tx.ExecContext(ctx, query, token, userID, time.Now().Add(exp)); err != nil {
+			return err
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
+
+// ResetPassword consumes the reset token, stores the new password and
+// revokes every session, personal access token and outstanding reset token
+// of the user.
+func (s *PasswordStore) ResetPassword(ctx context.Context, token []byte, hash []byte) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		userID, err := s.useReset(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+
+		if err := s.updatePassword(ctx, tx, userID, hash); err != nil {
+			return err
+		}
+
+		if err := s.invalidateResets(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		if err := deleteUserTokens(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		return revokeUserSessions(ctx, tx, userID, 0)
+	})
+}
+
+func (s *PasswordStore) useReset(ctx context.Context, tx *sql.Tx, token []byte) (int64, error) {
+	query := `
+		UPDATE password_resets
+		SET used_at = NOW()
+		WHERE token_hash = $1 AND used_at IS NULL AND expiry > NOW()
+		RETURNING user_id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	var userID int64
+	if err := tx.QueryRowContext(ctx, query, token).Scan(&userID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return userID, nil
+}
+
+func (s *PasswordStore) invalidateResets(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `
+		UPDATE password_resets
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *PasswordStore) updatePassword(ctx context.Context, tx *sql.Tx, userID int64, hash []byte) error {
+	query := `
+		UPDATE users
+		SET password = $1, updated_at = NOW()
+		WHERE id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := tx.ExecContext(ctx, query, hash, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
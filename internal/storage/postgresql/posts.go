@@ -6,33 +6,52 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/lib/pq"
 )
 
+const (
+	PostDraft     = "draft"
+	PostScheduled = "scheduled"
+	PostPublished = "published"
+)
+
 type Post struct {
-	ID        int64       `json:"id"`
-	UserID    int64       `json:"user_id"`
-	Title     string      `json:"title"`
-	Content   string      `json:"content"`
-	Tags      []string    `json:"tags"`
-	CreatedAt string      `json:"created_at"`
-	UpdatedAt string      `json:"updated_at"`
-	IsEdited  bool        `json:"is_edited"`
-	Images    []ImagePost `json:"images"`
-	User      User        `json:"user"`
+	ID          int64       `json:"id"`
+	UserID      int64       `json:"user_id"`
+	Title       string      `json:"title"`
+	Content     string      `json:"content"`
+	Tags        []string    `json:"tags"`
+	CreatedAt   string      `json:"created_at"`
+	UpdatedAt   string      `json:"updated_at"`
+	IsEdited    bool        `json:"is_edited"`
+	Images      []ImagePost `json:"images"`
+	User        User        `json:"user"`
+	DeletedAt   *time.Time  `json:"deleted_at"`
+	DeletedBy   *int64      `json:"deleted_by"`
+	Status      string      `json:"status"`
+	PublishAt   *time.Time  `json:"publish_at"`
+	PublishedAt *time.Time  `json:"published_at"`
+	Visibility  string      `json:"visibility"`
 }
 
 type ImagePost struct {
+	ID        int64  `json:"id"`
 	ImageName string `json:"image_name"`
 	PostID    int64  `json:"post_id"`
 	ImageURL  string `json:"image_url"`
+	Position  int    `json:"position"`
+	PublicID  string `json:"public_id"`
+	AltText   string `json:"alt_text"`
 	CreatedAt string `json:"created_at"`
 	UpdatedAt string `json:"updated_at"`
 }
 
 type PostWithMetaData struct {
 	Post
+	CommentCount int64
+	Reactions    map[string]int64
 }
 type PostStore struct {
 	db *sql.DB
@@ -40,8 +59,8 @@ type PostStore struct {
 
 func (p *PostStore) insertPost(ctx context.Context, tx *sql.Tx, post *Post) (*Post, error) {
 	query := `
-		INSERT INTO posts (user_id, title, content, tags)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO posts (user_id, title, content, tags, status, publish_at, published_at, visibility)
+		VALUES ($1, $2, $3, $4, $5, $6, CASE WHEN $7 THEN NOW() END, $8)
 		RETURNING id, created_at
 	`
 
@@ -52,6 +71,10 @@ func (p *PostStore) insertPost(ctx context.Context, tx *sql.Tx, post *Post) (*Po
 		post.Title,
 		post.Content,
 		pq.Array(post.Tags),
+		post.Status,
+		post.PublishAt,
+		post.Status == PostPublished,
+		post.Visibility,
 	).Scan(
 		&post.ID,
 		&post.CreatedAt,
@@ -64,10 +87,10 @@ func (p *PostStore) insertPost(ctx context.Context, tx *sql.Tx, post *Post) (*Po
 
 func (s *PostStore) insertImage(ctx context.Context, tx *sql.Tx, postID int64, imagePost ImagePost) error {
 	query := `
-		INSERT INTO images_post (image_name, image_url, post_id)
-		VALUES ($1, $2, $3)
+		INSERT INTO images_post (image_name, image_url, post_id, position, public_id, alt_text)
+		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := tx.ExecContext(ctx, query, imagePost.ImageName, imagePost.ImageURL, postID)
+	_, err := tx.ExecContext(ctx, query, imagePost.ImageName, imagePost.ImageURL, postID, imagePost.Position, imagePost.PublicID, imagePost.AltText)
 	if err != nil {
 		return fmt.Errorf("failed to insertt image, error : %v", err)
 	}
@@ -85,6 +108,14 @@ func (s *PostStore) CreatePost(ctx context.Context, p *Post, images []ImagePost)
 			return err
 		}
 
+		if err := syncMentions(ctx, tx, user.ID, user.Content); err != nil {
+			return err
+		}
+
+		if err := syncTags(ctx, tx, user.ID, user.Tags); err != nil {
+			return err
+		}
+
 		for _, image := range images {
 			if err := s.insertImage(ctx, tx, user.ID, image); err != nil {
 				return err
@@ -95,11 +126,16 @@ func (s *PostStore) CreatePost(ctx context.Context, p *Post, images []ImagePost)
 	})
 }
 
-func (s *PostStore) GetByID(ctx context.Context, tx *sql.Tx, postID int64) (*Post, error) {
+// GetByID returns the post if the viewer may read it, a post they can't see
+// is reported as not found so its existence doesn't leak.
+func (s *PostStore) GetByID(ctx context.Context, tx *sql.Tx, postID int64, viewer Viewer) (*Post, error) {
 	query := `
-		SELECT id, user_id, title, content, tags, created_at, updated_at, is_edited
-		FROM posts
-		WHERE id = $1
+		SELECT p.id, p.user_id, (SELECT username FROM users WHERE id = p.user_id), p.title, p.content, p.tags,
+			p.created_at, p.updated_at, p.is_edited, p.visibility
+		FROM posts p
+		WHERE p.id = $1 AND p.deleted_at IS NULL AND p.status = 'published' AND NOT EXISTS (
+			SELECT 1 FROM users u WHERE u.id = p.user_id AND u.deleted_at IS NOT NULL
+		) AND ($3 OR ` + visibleTo(2) + `)
 	`
 
 	post := new(Post)
@@ -107,15 +143,19 @@ func (s *PostStore) GetByID(ctx context.Context, tx *sql.Tx, postID int64) (*Pos
 		ctx,
 		query,
 		postID,
+		viewer.UserID,
+		viewer.ReadAny,
 	).Scan(
 		&post.ID,
 		&post.UserID,
+		&post.User.Username,
 		&post.Title,
 		&post.Content,
 		pq.Array(&post.Tags),
 		&post.CreatedAt,
 		&post.UpdatedAt,
 		&post.IsEdited,
+		&post.Visibility,
 	); err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -130,9 +170,10 @@ func (s *PostStore) GetByID(ctx context.Context, tx *sql.Tx, postID int64) (*Pos
 
 func (s *PostStore) getImageByID(ctx context.Context, tx *sql.Tx, postID int64) ([]ImagePost, error) {
 	query := `
-		SELECT image_name, image_url, post_id, created_at
+		SELECT id, image_name, image_url, post_id, position, COALESCE(public_id, ''), alt_text, created_at
 		FROM images_post
 		WHERE post_id = $1
+		ORDER BY position, id
 	`
 
 	rows, err := tx.QueryContext(
@@ -152,9 +193,13 @@ func (s *PostStore) getImageByID(ctx context.Context, tx *sql.Tx, postID int64)
 
 	for rows.Next() {
 		if err := rows.Scan(
+			&image.ID,
 			&image.ImageName,
 			&image.ImageURL,
 			&image.PostID,
+			&image.Position,
+			&image.PublicID,
+			&image.AltText,
 			&image.CreatedAt,
 		); err != nil {
 			return nil, err
@@ -169,7 +214,7 @@ func (s *PostStore) getImageByID(ctx context.Context, tx *sql.Tx, postID int64)
 	return images, nil
 }
 
-func (s *PostStore) GetPostByID(ctx context.Context, postID int64) (*Post, error) {
+func (s *PostStore) GetPostByID(ctx context.Context, postID int64, viewer Viewer) (*Post, error) {
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
@@ -177,7 +222,7 @@ func (s *PostStore) GetPostByID(ctx context.Context, postID int64) (*Post, error
 
 	return result, withTx(s.db, ctx, func(tx *sql.Tx) error {
 		// fetch post
-		post, err := s.GetByID(ctx, tx, postID)
+		post, err := s.GetByID(ctx, tx, postID, viewer)
 		if err != nil {
 			return err
 		}
@@ -194,69 +239,86 @@ func (s *PostStore) GetPostByID(ctx context.Context, postID int64) (*Post, error
 	})
 }
 
-func (s *PostStore) UpdatePost(ctx context.Context, p *Post) error {
+// UpdatePost keeps the version it replaces as a revision, editorID is who
+// made the edit.
+func (s *PostStore) UpdatePost(ctx context.Context, p *Post, editorID int64, event *AuditEvent) error {
 	query := `
 		UPDATE posts
-		SET title = $1, content = $2, tags = $3, is_edited = true, updated_at = NOW()
-		WHERE id = $4
+		SET title = $1, content = $2, tags = $3, visibility = $4, is_edited = true, updated_at = NOW()
+		WHERE id = $5
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	res, err := s.db.ExecContext(ctx, query, p.Title, p.Content, pq.Array(p.Tags), p.ID)
-	if err != nil {
-		switch {
-		case errors.Is(err, sql.ErrNoRows):
-			return ErrNotFound
-		default:
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		if err := insertRevision(ctx, tx, p.ID, editorID); err != nil {
 			return err
 		}
-	}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+		res, err := tx.ExecContext(ctx, query, p.Title, p.Content, pq.Array(p.Tags), p.Visibility, p.ID)
+		if err != nil {
+			return err
+		}
 
-	if rows == 0 {
-		return ErrNotFound
-	}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if rows == 0 {
+			return ErrNotFound
+		}
+
+		if err := syncMentions(ctx, tx, p.ID, p.Content); err != nil {
+			return err
+		}
+
+		if err := syncTags(ctx, tx, p.ID, p.Tags); err != nil {
+			return err
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
 }
 
-func (s *PostStore) DeletePost(ctx context.Context, postID int64) error {
+// DeletePost moves the post to the trash, it is purged for good once the
+// retention period is over.
+func (s *PostStore) DeletePost(ctx context.Context, postID, deletedBy int64, event *AuditEvent) error {
 	query := `
-		DELETE FROM posts
-		WHERE id = $1
+		UPDATE posts
+		SET deleted_at = NOW(), deleted_by = $2
+		WHERE id = $1 AND deleted_at IS NULL
 	`
 
 	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
 	defer cancel()
 
-	res, err := s.db.ExecContext(ctx, query, postID)
-	if err != nil {
-		return err
-	}
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		res, err := tx.ExecContext(ctx, query, postID, deletedBy)
+		if err != nil {
+			return err
+		}
 
-	rows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-	if rows == 0 {
-		return ErrNotFound
-	}
+		if rows == 0 {
+			return ErrNotFound
+		}
 
-	return nil
+		return insertAuditEvent(ctx, tx, event)
+	})
 }
 
-func (s *PostStore) GetByUser(ctx context.Context, userID int64) (*[]Post, error) {
+// GetByUser lists the published posts of a user the viewer may read.
+func (s *PostStore) GetByUser(ctx context.Context, userID, viewerID int64) (*[]Post, error) {
 	query := `
-	SELECT id, title, content, tags, is_edited
-	FROM posts
-	WHERE user_id = $1
+	SELECT p.id, p.title, p.content, p.tags, p.is_edited
+	FROM posts p
+	WHERE p.user_id = $1 AND p.deleted_at IS NULL AND p.status = 'published' AND ` + visibleTo(2) + `
 	`
 
 	var (
@@ -264,7 +326,7 @@ func (s *PostStore) GetByUser(ctx context.Context, userID int64) (*[]Post, error
 		posts []Post
 	)
 
-	rows, err := s.db.QueryContext(ctx, query, userID)
+	rows, err := s.db.QueryContext(ctx, query, userID, viewerID)
 	if err != nil {
 		switch {
 		case errors.Is(err, sql.ErrNoRows):
@@ -313,14 +375,18 @@ func (s *PostStore) GetFeeds(ctx context.Context, userID int64, pf Pagination) (
 		FROM posts p
 		LEFT JOIN users u ON u.id = p.user_id
 		LEFT JOIN follows f ON f.user_id = p.user_id AND f.follower_id = $1
-			WHERE p.user_id = $1  -- Own posts
-   		OR f.user_id IS NOT NULL
+			WHERE (p.user_id = $1  -- Own posts
+   		OR f.user_id IS NOT NULL)
+		AND u.deleted_at IS NULL
+		AND p.deleted_at IS NULL
+		AND p.status = 'published'
+		AND ` + visibleTo(1) + `
 	`)
 
 	params = append(params, userID)
 
 	// condition query
-	queryBuilder.WriteString(` GROUP BY p.id, u.username ORDER BY p.created_at ` + pf.Sort)
+	queryBuilder.WriteString(` GROUP BY p.id, u.username ORDER BY p.published_at ` + pf.Sort)
 	queryBuilder.WriteString(fmt.Sprintf(" LIMIT $%d OFFSET $%d", paramsCount+1, paramsCount+2))
 	params = append(params, pf.Limit, pf.Offset)
 
@@ -333,7 +399,10 @@ func (s *PostStore) GetFeeds(ctx context.Context, userID int64, pf Pagination) (
 	}
 	defer rows.Close()
 
-	var feeds []PostWithMetaData
+	var (
+		feeds []PostWithMetaData
+		ids   []int64
+	)
 
 	for rows.Next() {
 		var feed PostWithMetaData
@@ -351,23 +420,22 @@ func (s *PostStore) GetFeeds(ctx context.Context, userID int64, pf Pagination) (
 			return nil, fmt.Errorf("failed to scan row: %w", err)
 		}
 
-		tx, err := s.db.BeginTx(ctx, nil)
-		if err != nil {
-			return nil, fmt.Errorf("failed to begin transaction: %w", err)
-		}
-		images, err := s.getImageByID(ctx, tx, feed.ID)
-		if err != nil {
-			return nil, err
-		}
-
-		feed.Images = images
-
 		feeds = append(feeds, feed)
+		ids = append(ids, feed.ID)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("error for iterating rows :%w", err)
 	}
 
+	images, err := getImagesByPosts(ctx, s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range feeds {
+		feeds[i].Images = images[feeds[i].ID]
+	}
+
 	return feeds, nil
 }
@@ -0,0 +1,165 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// PostRevision is a version of a post as it was before an edit replaced it.
+// VersionCreatedAt is when that version was written, CreatedAt when it was
+// replaced and EditedBy who replaced it.
+type PostRevision struct {
+	ID               int64    `json:"id"`
+	PostID           int64    `json:"post_id"`
+	Revision         int      `json:"revision"`
+	Title            string   `json:"title"`
+	Content          string   `json:"content"`
+	Tags             []string `json:"tags"`
+	EditedBy         *int64   `json:"edited_by"`
+	EditedByUsername string   `json:"edited_by_username"`
+	VersionCreatedAt string   `json:"version_created_at"`
+	CreatedAt        string   `json:"created_at"`
+}
+
+type RevisionStore struct {
+	db *sql.DB
+}
+
+const revisionColumns = `
+	r.id, r.post_id, r.revision, r.title, r.content, r.tags, r.edited_by,
+	COALESCE(u.username, ''), r.version_created_at, r.created_at
+`
+
+// insertRevision copies the current version of the post into its history,
+// the post row stays locked until the transaction that edits it commits.
+func insertRevision(ctx context.Context, tx *sql.Tx, postID, editorID int64) error {
+	var (
+		title, content string
+		tags           []string
+		updatedAt      string
+	)
+
+	if err := tx.QueryRowContext(ctx, `
+		SELECT title, content, tags, updated_at
+		FROM posts
+		WHERE id = $1
+		FOR UPDATE
+	`, postID).Scan(
+		&title,
+		&content,
+		pq.Array(&tags),
+		&updatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return ErrNotFound
+		default:
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO post_revisions (post_id, revision, title, content, tags, edited_by, version_created_at)
+		SELECT $1, COALESCE(MAX(revision), 0) + 1, $2, $3, $4, $5, $6
+		FROM post_revisions
+		WHERE post_id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, postID, title, content, pq.Array(tags), editorID, updatedAt)
+	return err
+}
+
+func (s *RevisionStore) GetByPost(ctx context.Context, postID int64) ([]PostRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM post_revisions r
+		LEFT JOIN users u ON u.id = r.edited_by
+		WHERE r.post_id = $1
+		ORDER BY r.revision DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []PostRevision{}
+	for rows.Next() {
+		var r PostRevision
+		if err := scanRevision(rows, &r); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, r)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (s *RevisionStore) GetByRevision(ctx context.Context, postID int64, revision int) (*PostRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM post_revisions r
+		LEFT JOIN users u ON u.id = r.edited_by
+		WHERE r.post_id = $1 AND r.revision = $2
+	`
+
+	return s.getOne(ctx, query, postID, revision)
+}
+
+// GetNext returns the revision that replaced the given one, ErrNotFound means
+// the post itself replaced it.
+func (s *RevisionStore) GetNext(ctx context.Context, postID int64, revision int) (*PostRevision, error) {
+	query := `
+		SELECT ` + revisionColumns + `
+		FROM post_revisions r
+		LEFT JOIN users u ON u.id = r.edited_by
+		WHERE r.post_id = $1 AND r.revision > $2
+		ORDER BY r.revision
+		LIMIT 1
+	`
+
+	return s.getOne(ctx, query, postID, revision)
+}
+
+func (s *RevisionStore) getOne(ctx context.Context, query string, postID int64, revision int) (*PostRevision, error) {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	r := new(PostRevision)
+	if err := scanRevision(s.db.QueryRowContext(ctx, query, postID, revision), r); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
+
+func scanRevision(row rowScanner, r *PostRevision) error {
+	return row.Scan(
+		&r.ID,
+		&r.PostID,
+		&r.Revision,
+		&r.Title,
+		&r.Content,
+		pq.Array(&r.Tags),
+		&r.EditedBy,
+		&r.EditedByUsername,
+		&r.VersionCreatedAt,
+		&r.CreatedAt,
+	)
+}
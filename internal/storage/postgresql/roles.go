@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+
+	"github.com/lib/pq"
 )
 
 type RoleStore struct {
@@ -11,8 +13,15 @@ type RoleStore struct {
 }
 
 type Role struct {
+	ID          int64    `json:"id"`
+	Level       int64    `json:"level"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+type Permission struct {
 	ID          int64  `json:"id"`
-	Level       int64  `json:"level"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 }
@@ -41,3 +50,142 @@ func (s *RoleStore) GetByName(ctx context.Context, roleName string) (*Role, erro
 
 	return role, nil
 }
+
+// GetAll returns every role with the names of the permissions granted to it.
+func (s *RoleStore) GetAll(ctx context.Context) ([]Role, error) {
+	query := `
+		SELECT r.id, r.level, r.name, r.description,
+			ARRAY(
+				SELECT p.name
+				FROM role_permissions rp
+				JOIN permissions p ON p.id = rp.permission_id
+				WHERE rp.role_id = r.id
+				ORDER BY p.name
+			)
+		FROM roles r
+		ORDER BY r.level, r.id
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	roles := []Role{}
+	for rows.Next() {
+		var role Role
+		if err := rows.Scan(
+			&role.ID,
+			&role.Level,
+			&role.Name,
+			&role.Description,
+			pq.Array(&role.Permissions),
+		); err != nil {
+			return nil, err
+		}
+		roles = append(roles, role)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+func (s *RoleStore) GetPermissions(ctx context.Context) ([]Permission, error) {
+	query := `
+		SELECT id, name, description
+		FROM permissions
+		ORDER BY name
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	permissions := []Permission{}
+	for rows.Next() {
+		var p Permission
+		if err := rows.Scan(
+			&p.ID,
+			&p.Name,
+			&p.Description,
+		); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return permissions, nil
+}
+
+// GrantPermission gives the role a permission, granting one it already has is
+// a no-op and is not audited.
+func (s *RoleStore) GrantPermission(ctx context.Context, roleID int64, permission string, event *AuditEvent) error {
+	query := `
+		INSERT INTO role_permissions (role_id, permission_id)
+		SELECT $1, id
+		FROM permissions
+		WHERE name = $2
+		ON CONFLICT DO NOTHING
+	`
+
+	return s.changePermission(ctx, query, roleID, permission, event)
+}
+
+func (s *RoleStore) RevokePermission(ctx context.Context, roleID int64, permission string, event *AuditEvent) error {
+	query := `
+		DELETE FROM role_permissions
+		WHERE role_id = $1 AND permission_id = (
+			SELECT id FROM permissions WHERE name = $2
+		)
+	`
+
+	return s.changePermission(ctx, query, roleID, permission, event)
+}
+
+func (s *RoleStore) changePermission(ctx context.Context, query string, roleID int64, permission string, event *AuditEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		var exists bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM permissions WHERE name = $1)`, permission).Scan(&exists); err != nil {
+			return err
+		}
+
+		if !exists {
+			return ErrNotFound
+		}
+
+		res, err := tx.ExecContext(ctx, query, roleID, permission)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return nil
+		}
+
+		return insertAuditEvent(ctx, tx, event)
+	})
+}
@@ -0,0 +1,230 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// HighlightStart and HighlightStop delimit the matches ts_headline finds.
+// They are private use characters rather than markup, the text around them is
+// user content that gets escaped before the markers become <mark> tags.
+const (
+	HighlightStart = "\ue000"
+	HighlightStop  = "\ue001"
+)
+
+const highlightOptions = `StartSel="` + HighlightStart + `", StopSel="` + HighlightStop + `"`
+
+// stripMarkers removes the markers from user content so only ts_headline
+// can place them.
+func stripMarkers(column string) string {
+	return `translate(` + column + `, '` + HighlightStart + HighlightStop + `', '')`
+}
+
+// SearchQuery is one page of a search. Results are ordered by rank, then id,
+// both descending, and After continues from the last result of a page.
+type SearchQuery struct {
+	Text     string
+	ViewerID int64
+	Limit    int
+	After    *SearchCursor
+}
+
+type SearchCursor struct {
+	Rank float32
+	ID   int64
+}
+
+func (q SearchQuery) cursor() (any, any) {
+	if q.After == nil {
+		return nil, nil
+	}
+	return q.After.Rank, q.After.ID
+}
+
+type PostHit struct {
+	ID          int64
+	UserID      int64
+	Username    string
+	Title       string
+	Snippet     string
+	Tags        []string
+	PublishedAt *time.Time
+	Rank        float32
+}
+
+type UserHit struct {
+	ID       int64
+	Username string
+	Fullname string
+	ImageURL string
+	Rank     float32
+}
+
+type TagHit struct {
+	ID    int64
+	Name  string
+	Posts int64
+	Rank  float32
+}
+
+// SearchStore searches with the database, full text on posts and trigrams on
+// users and tags.
+type SearchStore struct {
+	db *sql.DB
+}
+
+// likePattern matches text anywhere, with the wildcards in it escaped.
+func likePattern(text string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text) + "%"
+}
+
+func (s *SearchStore) Posts(ctx context.Context, q SearchQuery) ([]PostHit, error) {
+	query := `
+		SELECT p.id, p.user_id, u.username,
+			ts_headline('simple', ` + stripMarkers("p.title") + `, tq, 'HighlightAll=true, ` + highlightOptions + `'),
+			ts_headline('simple', ` + stripMarkers("p.content") + `, tq, 'MaxFragments=2, ` + highlightOptions + `'),
+			p.tags, p.published_at, ts_rank(p.search_vector, tq) AS rank
+		FROM posts p
+		JOIN users u ON u.id = p.user_id
+		CROSS JOIN websearch_to_tsquery('simple', $1) tq
+		WHERE p.search_vector @@ tq
+			AND p.deleted_at IS NULL
+			AND p.status = 'published'
+			AND u.deleted_at IS NULL
+			AND ` + visibleTo(2) + `
+			AND ` + notBlocked(2, "p.user_id") + `
+			AND ($3::real IS NULL OR (ts_rank(p.search_vector, tq), p.id) < ($3::real, $4::bigint))
+		ORDER BY rank DESC, p.id DESC
+		LIMIT $5
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rank, id := q.cursor()
+	rows, err := s.db.QueryContext(ctx, query, q.Text, q.ViewerID, rank, id, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []PostHit{}
+	for rows.Next() {
+		var hit PostHit
+		if err := rows.Scan(
+			&hit.ID,
+			&hit.UserID,
+			&hit.Username,
+			&hit.Title,
+			&hit.Snippet,
+			pq.Array(&hit.Tags),
+			&hit.PublishedAt,
+			&hit.Rank,
+		); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+func (s *SearchStore) Users(ctx context.Context, q SearchQuery) ([]UserHit, error) {
+	query := `
+		SELECT id, username, fullname, image_url, rank
+		FROM (
+			SELECT u.id, u.username, u.fullname, COALESCE(img.image_url, '') AS image_url,
+				GREATEST(similarity(u.username, $1), similarity(u.fullname, $1))::real AS rank
+			FROM users u
+			LEFT JOIN image_profile img ON img.user_id = u.id
+			WHERE (u.username % $1 OR u.fullname % $1 OR u.username ILIKE $2 OR u.fullname ILIKE $2)
+				AND u.is_active AND u.deleted_at IS NULL
+				AND ` + notBlocked(6, "u.id") + `
+		) hits
+		WHERE $3::real IS NULL OR (rank, id) < ($3::real, $4::bigint)
+		ORDER BY rank DESC, id DESC
+		LIMIT $5
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rank, id := q.cursor()
+	rows, err := s.db.QueryContext(ctx, query, q.Text, likePattern(q.Text), rank, id, q.Limit, q.ViewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []UserHit{}
+	for rows.Next() {
+		var hit UserHit
+		if err := rows.Scan(
+			&hit.ID,
+			&hit.Username,
+			&hit.Fullname,
+			&hit.ImageURL,
+			&hit.Rank,
+		); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
+
+// Tags only counts the posts anyone can see, left out with their author
+// once the account is deleted, like trending tags.
+func (s *SearchStore) Tags(ctx context.Context, q SearchQuery) ([]TagHit, error) {
+	query := `
+		SELECT id, name, posts, rank
+		FROM (
+			SELECT t.id, t.name, similarity(t.name, $1)::real AS rank,
+				(
+					SELECT COUNT(*)
+					FROM post_tags pt
+					JOIN posts p ON p.id = pt.post_id
+					JOIN users u ON u.id = p.user_id
+					WHERE pt.tag_id = t.id AND p.status = 'published' AND p.visibility = 'public' AND p.deleted_at IS NULL
+						AND u.deleted_at IS NULL
+				) AS posts
+			FROM tags t
+			WHERE t.name % $1 OR t.name LIKE $2
+		) hits
+		WHERE $3::real IS NULL OR (rank, id) < ($3::real, $4::bigint)
+		ORDER BY rank DESC, id DESC
+		LIMIT $5
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rank, id := q.cursor()
+	rows, err := s.db.QueryContext(ctx, query, q.Text, likePattern(q.Text), rank, id, q.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	hits := []TagHit{}
+	for rows.Next() {
+		var hit TagHit
+		if err := rows.Scan(
+			&hit.ID,
+			&hit.Name,
+			&hit.Posts,
+			&hit.Rank,
+		); err != nil {
+			return nil, err
+		}
+		hits = append(hits, hit)
+	}
+
+	return hits, rows.Err()
+}
@@ -0,0 +1,287 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Session struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	TokenHash  []byte     `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	LastSeenAt time.Time  `json:"last_seen_at"`
+	CreatedAt  string     `json:"created_at"`
+	UpdatedAt  string     `json:"updated_at"`
+}
+
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+type SessionStore struct {
+	db *sql.DB
+}
+
+func (s *SessionStore) CreateSession(ctx context.Context, session *Session) error {
+	query := `
+		INSERT INTO sessions (user_id, token_hash, user_agent, ip_address, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, last_seen_at, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return s.db.QueryRowContext(
+		ctx,
+		query,
+		session.UserID,
+		session.TokenHash,
+		session.UserAgent,
+		session.IPAddress,
+		session.ExpiresAt,
+	).Scan(
+		&session.ID,
+		&session.LastSeenAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	)
+}
+
+func (s *SessionStore) GetByID(ctx context.Context, sessionID int64) (*Session, error) {
+	query := `
+		SELECT id, user_id, token_hash, user_agent, ip_address, expires_at, revoked_at, last_seen_at, created_at, updated_at
+		FROM sessions
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return s.scanSession(s.db.QueryRowContext(ctx, query, sessionID))
+}
+
+// GetByTokenHash looks a session up by its current refresh token. When the
+// hash only matches a token that was already rotated out, ErrTokenReused is
+// returned together with the session so the caller can revoke it.
+func (s *SessionStore) GetByTokenHash(ctx context.Context, hash []byte) (*Session, error) {
+	query := `
+		SELECT id, user_id, token_hash, user_agent, ip_address, expires_at, revoked_at, last_seen_at, created_at, updated_at
+		FROM sessions
+		WHERE token_hash = $1 OR previous_token_hash = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	session, err := s.scanSession(s.db.QueryRowContext(ctx, query, hash))
+	if err != nil {
+		return nil, err
+	}
+
+	if string(session.TokenHash) != string(hash) {
+		return session, ErrTokenReused
+	}
+
+	return session, nil
+}
+
+func (s *SessionStore) RotateToken(ctx context.Context, session *Session, newHash []byte, expiresAt time.Time) error {
+	query := `
+		UPDATE sessions
+		SET previous_token_hash = token_hash, token_hash = $1, expires_at = $2, last_seen_at = NOW(), updated_at = NOW()
+		WHERE id = $3 AND token_hash = $4 AND revoked_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, newHash, expiresAt, session.ID, session.TokenHash)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	// another request rotated the token first
+	if rows == 0 {
+		return ErrTokenReused
+	}
+
+	session.TokenHash = newHash
+	session.ExpiresAt = expiresAt
+	return nil
+}
+
+func (s *SessionStore) RevokeSession(ctx context.Context, sessionID int64) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, sessionID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *SessionStore) GetActiveByUser(ctx context.Context, userID int64) ([]Session, error) {
+	query := `
+		SELECT id, user_id, user_agent, ip_address, expires_at, last_seen_at, created_at, updated_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+		ORDER BY last_seen_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var session Session
+		if err := rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.UserAgent,
+			&session.IPAddress,
+			&session.ExpiresAt,
+			&session.LastSeenAt,
+			&session.CreatedAt,
+			&session.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
+// RevokeUserSession revokes a session only when it belongs to the user.
+func (s *SessionStore) RevokeUserSession(ctx context.Context, userID, sessionID int64) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *SessionStore) TouchSession(ctx context.Context, sessionID int64, ip string) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = NOW(), ip_address = $1
+		WHERE id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := s.db.ExecContext(ctx, query, ip, sessionID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *SessionStore) scanSession(row *sql.Row) (*Session, error) {
+	session := new(Session)
+	if err := row.Scan(
+		&session.ID,
+		&session.UserID,
+		&session.TokenHash,
+		&session.UserAgent,
+		&session.IPAddress,
+		&session.ExpiresAt,
+		&session.RevokedAt,
+		&session.LastSeenAt,
+		&session.CreatedAt,
+		&session.UpdatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return session, nil
+}
+
+// RevokeAllByUser revokes every active session of the user except the one
+// given, pass 0 to log the user out everywhere.
+func (s *SessionStore) RevokeAllByUser(ctx context.Context, userID, exceptSessionID int64) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		return revokeUserSessions(ctx, tx, userID, exceptSessionID)
+	})
+}
+
+func revokeUserSessions(ctx context.Context, tx *sql.Tx, userID, exceptSessionID int64) error {
+	query := `
+		UPDATE sessions
+		SET revoked_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1 AND id <> $2 AND revoked_at IS NULL
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, userID, exceptSessionID); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -12,6 +12,7 @@ var (
 	ErrDuplicateEmail    = errors.New("email already exists")
 	ErrDuplicateUsername = errors.New("username already exists")
 	ErrConflict          = errors.New("resource already exists")
+	ErrTokenReused       = errors.New("token has already been used")
 	TimeoutCtx           = time.Second * 5
 )
 
@@ -22,33 +23,141 @@ type Storage struct {
 		CreateUser(context.Context, *User, *ImgURL) error
 		UpdateProfile(context.Context, *ImgURL) error
 		UpdateUser(context.Context, *User) error
+		CreateAndInvite(context.Context, *User, *ImgURL, []byte, time.Duration) error
+		Activate(context.Context, []byte) error
+		Delete(context.Context, int64) error
+		CreateWithIdentity(context.Context, *User, *ImgURL, *Identity) error
 	}
 	Posts interface {
 		CreatePost(context.Context, *Post, []ImagePost) error
-		UpdatePost(context.Context, *Post) error
-		GetPostByID(context.Context, int64) (*Post, error)
-		GetByID(context.Context, *sql.Tx, int64) (*Post, error)
-		DeletePost(context.Context, int64) error
-		GetByUser(context.Context, int64) (*[]Post, error)
+		UpdatePost(context.Context, *Post, int64, *AuditEvent) error
+		GetPostByID(context.Context, int64, Viewer) (*Post, error)
+		GetByID(context.Context, *sql.Tx, int64, Viewer) (*Post, error)
+		DeletePost(context.Context, int64, int64, *AuditEvent) error
+		GetByUser(context.Context, int64, int64) (*[]Post, error)
 		GetFeeds(context.Context, int64, Pagination) ([]PostWithMetaData, error)
+		GetTrash(context.Context, int64) ([]Post, error)
+		RestorePost(context.Context, int64, int64, time.Time) error
+		PurgeDeleted(context.Context, time.Time, int) ([]PurgedPost, error)
+		GetDrafts(context.Context, int64) ([]Post, error)
+		GetDraft(context.Context, int64, int64) (*Post, error)
+		UpdateDraft(context.Context, *Post) error
+		PublishDue(context.Context, int) ([]int64, error)
+		GetImages(context.Context, int64) ([]ImagePost, error)
+		AddImages(context.Context, int64, []ImagePost, *AuditEvent) error
+		RemoveImage(context.Context, int64, int64, *AuditEvent) (*ImagePost, error)
+		ReorderImages(context.Context, int64, []int64, *AuditEvent) error
+		UpdateImageAltText(context.Context, int64, int64, string, *AuditEvent) error
+	}
+	Revisions interface {
+		GetByPost(context.Context, int64) ([]PostRevision, error)
+		GetByRevision(context.Context, int64, int) (*PostRevision, error)
+		GetNext(context.Context, int64, int) (*PostRevision, error)
+	}
+	Tags interface {
+		GetPosts(context.Context, string, int64, Pagination) ([]PostWithMetaData, error)
+		GetTrending(context.Context, time.Duration, time.Duration, int) ([]TrendingTag, error)
+	}
+	Search interface {
+		Posts(context.Context, SearchQuery) ([]PostHit, error)
+		Users(context.Context, SearchQuery) ([]UserHit, error)
+		Tags(context.Context, SearchQuery) ([]TagHit, error)
 	}
 	Roles interface {
 		GetByName(context.Context, string) (*Role, error)
+		GetAll(context.Context) ([]Role, error)
+		GetPermissions(context.Context) ([]Permission, error)
+		GrantPermission(context.Context, int64, string, *AuditEvent) error
+		RevokePermission(context.Context, int64, string, *AuditEvent) error
 	}
 	Follows interface {
 		FollowUser(context.Context, int64, int64) error
 		UnfollowUser(context.Context, int64, int64) error
 	}
+	Blocks interface {
+		BlockUser(context.Context, int64, int64) error
+		UnblockUser(context.Context, int64, int64) error
+	}
 	Activities interface {
-		ToggleLikePost(context.Context, *Activities) error
-		ToggleDislikePost(context.Context, *Activities) error
-		GetLikesByPost(context.Context, int64) (int64, error)
-		GetDislikesByPost(context.Context, int64) (int64, error)
+		GetReactions(context.Context) ([]Reaction, error)
+		ReactPost(context.Context, *Activities) error
+		ReactComment(context.Context, *Activities) error
+		GetReactionsByPost(context.Context, int64) (map[string]int64, error)
 	}
 	Comments interface {
 		CreateComments(context.Context, *Comment) error
-		GetCommentsByPost(context.Context, int64) ([]Comment, error)
 		GetCommentCountByPost(context.Context, int64) (int64, error)
+		GetByID(context.Context, int64, int64) (*Comment, error)
+		GetThreads(context.Context, int64, *int64, CommentPage, int) ([]CommentThread, error)
+		UpdateComment(context.Context, *Comment, int64) error
+		DeleteComment(context.Context, int64, int64, *AuditEvent) error
+		SetHidden(context.Context, int64, *int64, *AuditEvent) error
+		GetRevisions(context.Context, int64) ([]CommentRevision, error)
+	}
+	Sessions interface {
+		CreateSession(context.Context, *Session) error
+		GetByID(context.Context, int64) (*Session, error)
+		GetByTokenHash(context.Context, []byte) (*Session, error)
+		RotateToken(context.Context, *Session, []byte, time.Time) error
+		RevokeSession(context.Context, int64) error
+		RevokeAllByUser(context.Context, int64, int64) error
+		GetActiveByUser(context.Context, int64) ([]Session, error)
+		RevokeUserSession(context.Context, int64, int64) error
+		TouchSession(context.Context, int64, string) error
+	}
+	Passwords interface {
+		ChangePassword(context.Context, int64, []byte, int64) error
+		CreateReset(context.Context, int64, []byte, time.Duration) error
+		ResetPassword(context.Context, []byte, []byte) error
+		ForcePasswordReset(context.Context, int64, []byte, []byte, time.Duration, *AuditEvent) error
+	}
+	MFA interface {
+		GetByUser(context.Context, int64) (*MFA, error)
+		SetPendingSecret(context.Context, int64, string) error
+		Enable(context.Context, int64, int64, [][]byte) error
+		Disable(context.Context, int64) error
+		UseStep(context.Context, int64, int64) error
+		UseRecoveryCode(context.Context, int64, []byte) error
+	}
+	Tokens interface {
+		CreateToken(context.Context, *PersonalToken) error
+		GetByUser(context.Context, int64) ([]PersonalToken, error)
+		GetByID(context.Context, int64, int64) (*PersonalToken, error)
+		GetByHash(context.Context, []byte) (*PersonalToken, error)
+		UpdateToken(context.Context, *PersonalToken) error
+		DeleteToken(context.Context, int64, int64) error
+		TouchLastUsed(context.Context, int64) error
+	}
+	Identities interface {
+		GetByProviderSubject(context.Context, string, string) (*Identity, error)
+		GetByUser(context.Context, int64) ([]Identity, error)
+		CreateIdentity(context.Context, *Identity) error
+		DeleteIdentity(context.Context, int64, int64) error
+		SaveState(context.Context, *OIDCState) error
+		ConsumeState(context.Context, string) (*OIDCState, error)
+	}
+	Accounts interface {
+		Deactivate(context.Context, int64, *AuditEvent) (time.Time, error)
+		GetDeletedByEmail(context.Context, string) (*DeletedUser, error)
+		Restore(context.Context, int64, time.Time) error
+		PurgeDeleted(context.Context, time.Time, int) ([]PurgedUser, error)
+		Export(context.Context, int64) (*AccountExport, error)
+	}
+	Admin interface {
+		GetUsers(context.Context, UserFilter) ([]User, error)
+		GetUser(context.Context, int64) (*User, error)
+		UpdateRole(context.Context, int64, string, *AuditEvent) error
+		SetActive(context.Context, int64, bool, *AuditEvent) error
+	}
+	Audit interface {
+		CreateEvent(context.Context, *AuditEvent) error
+		GetEvents(context.Context, AuditFilter) ([]AuditEvent, error)
+	}
+	LoginAttempts interface {
+		CreateAttempt(context.Context, *LoginAttempt) error
+		CountFailuresByEmail(context.Context, string, time.Time) (int, time.Time, error)
+		CountFailuresByIP(context.Context, string, time.Time) (int, time.Time, error)
+		GetAttempts(context.Context, LoginAttemptFilter) ([]LoginAttempt, error)
 	}
 }
 
@@ -60,18 +169,57 @@ func NewStorage(db *sql.DB) Storage {
 		Posts: &PostStore{
 			db: db,
 		},
+		Revisions: &RevisionStore{
+			db: db,
+		},
+		Tags: &TagStore{
+			db: db,
+		},
+		Search: &SearchStore{
+			db: db,
+		},
 		Roles: &RoleStore{
 			db: db,
 		},
 		Follows: &FollowStore{
 			db: db,
 		},
+		Blocks: &BlockStore{
+			db: db,
+		},
 		Comments: &CommentStore{
 			db: db,
 		},
 		Activities: &UserActivities{
 			db: db,
 		},
+		Sessions: &SessionStore{
+			db: db,
+		},
+		Passwords: &PasswordStore{
+			db: db,
+		},
+		MFA: &MFAStore{
+			db: db,
+		},
+		Tokens: &TokenStore{
+			db: db,
+		},
+		Identities: &IdentityStore{
+			db: db,
+		},
+		Accounts: &AccountStore{
+			db: db,
+		},
+		Admin: &AdminStore{
+			db: db,
+		},
+		Audit: &AuditStore{
+			db: db,
+		},
+		LoginAttempts: &LoginAttemptStore{
+			db: db,
+		},
 	}
 }
 
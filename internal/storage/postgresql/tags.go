@@ -0,0 +1,202 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type TrendingTag struct {
+	Name  string  `json:"name"`
+	Uses  int64   `json:"uses"`
+	Score float64 `json:"score"`
+}
+
+type TagStore struct {
+	db *sql.DB
+}
+
+// syncTags makes post_tags match the tags of the post, the tags are expected
+// to be normalized already.
+func syncTags(ctx context.Context, tx *sql.Tx, postID int64, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	insertTags := `INSERT INTO tags (name) SELECT unnest($1::varchar[]) ON CONFLICT (name) DO NOTHING`
+	if _, err := tx.ExecContext(ctx, insertTags, pq.Array(tags)); err != nil {
+		return err
+	}
+
+	queries := []string{
+		`DELETE FROM post_tags pt
+		USING tags t
+		WHERE pt.tag_id = t.id AND pt.post_id = $1 AND NOT t.name = ANY($2)`,
+		`INSERT INTO post_tags (post_id, tag_id)
+		SELECT $1, id FROM tags WHERE name = ANY($2)
+		ON CONFLICT DO NOTHING`,
+	}
+
+	for _, query := range queries {
+		if _, err := tx.ExecContext(ctx, query, postID, pq.Array(tags)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetPosts lists the published posts carrying the tag that the viewer may
+// read, with their counts.
+func (s *TagStore) GetPosts(ctx context.Context, tag string, viewerID int64, pf Pagination) ([]PostWithMetaData, error) {
+	query := `
+		SELECT p.id, p.user_id, u.username, p.title, p.content, p.tags, p.is_edited, p.created_at, p.updated_at,
+			(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL AND c.hidden_at IS NULL)
+		FROM tags t
+		JOIN post_tags pt ON pt.tag_id = t.id
+		JOIN posts p ON p.id = pt.post_id
+		JOIN users u ON u.id = p.user_id
+		WHERE t.name = $1
+			AND u.deleted_at IS NULL
+			AND p.deleted_at IS NULL
+			AND p.status = 'published'
+			AND ` + visibleTo(2) + `
+		ORDER BY p.published_at ` + pf.Sort + `, p.id ` + pf.Sort + `
+		LIMIT $3 OFFSET $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, tag, viewerID, pf.Limit, pf.Offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute query :%w", err)
+	}
+	defer rows.Close()
+
+	posts := []PostWithMetaData{}
+	ids := []int64{}
+	for rows.Next() {
+		var post PostWithMetaData
+		if err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.User.Username,
+			&post.Title,
+			&post.Content,
+			pq.Array(&post.Tags),
+			&post.IsEdited,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.CommentCount,
+		); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+		ids = append(ids, post.ID)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	images, err := getImagesByPosts(ctx, s.db, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	reactions, err := s.getReactionsByPosts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range posts {
+		posts[i].Images = images[posts[i].ID]
+		posts[i].Reactions = reactions[posts[i].ID]
+	}
+
+	return posts, nil
+}
+
+// getReactionsByPosts counts the reactions of a page of posts by kind in one
+// query.
+func (s *TagStore) getReactionsByPosts(ctx context.Context, postIDs []int64) (map[int64]map[string]int64, error) {
+	query := `
+		SELECT post_id, reaction, COUNT(*)
+		FROM user_activities
+		WHERE post_id = ANY($1)
+		GROUP BY post_id, reaction
+	`
+
+	reactions := map[int64]map[string]int64{}
+	if len(postIDs) == 0 {
+		return reactions, nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, pq.Array(postIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			postID   int64
+			reaction string
+			count    int64
+		)
+		if err := rows.Scan(&postID, &reaction, &count); err != nil {
+			return nil, err
+		}
+		if reactions[postID] == nil {
+			reactions[postID] = map[string]int64{}
+		}
+		reactions[postID][reaction] = count
+	}
+
+	return reactions, rows.Err()
+}
+
+// GetTrending ranks the tags of public posts published within the window,
+// each use counts for less the older the post, halving every halfLife.
+func (s *TagStore) GetTrending(ctx context.Context, window, halfLife time.Duration, limit int) ([]TrendingTag, error) {
+	query := `
+		SELECT t.name, COUNT(*),
+			SUM(EXP(-LN(2) * EXTRACT(EPOCH FROM NOW() - p.published_at) / $2)) AS score
+		FROM posts p
+		JOIN post_tags pt ON pt.post_id = p.id
+		JOIN tags t ON t.id = pt.tag_id
+		JOIN users u ON u.id = p.user_id
+		WHERE p.published_at > NOW() - make_interval(secs => $1)
+			AND p.status = 'published'
+			AND p.visibility = 'public'
+			AND p.deleted_at IS NULL
+			AND u.deleted_at IS NULL
+		GROUP BY t.name
+		ORDER BY score DESC, t.name
+		LIMIT $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, window.Seconds(), halfLife.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []TrendingTag{}
+	for rows.Next() {
+		var tag TrendingTag
+		if err := rows.Scan(&tag.Name, &tag.Uses, &tag.Score); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
@@ -0,0 +1,257 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type PersonalToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name"`
+	TokenHash  []byte     `json:"-"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  string     `json:"created_at"`
+	UpdatedAt  string     `json:"updated_at"`
+}
+
+func (t *PersonalToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+func (t *PersonalToken) HasScope(scope string) bool {
+	for _, s := range t.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+type TokenStore struct {
+	db *sql.DB
+}
+
+func (s *TokenStore) CreateToken(ctx context.Context, t *PersonalToken) error {
+	query := `
+		INSERT INTO personal_access_tokens (user_id, name, token_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at, updated_at
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if err := s.db.QueryRowContext(
+		ctx,
+		query,
+		t.UserID,
+		t.Name,
+		t.TokenHash,
+		pq.Array(t.Scopes),
+		t.ExpiresAt,
+	).Scan(
+		&t.ID,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_user_token_name"`:
+			return ErrConflict
+		default:
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *TokenStore) GetByUser(ctx context.Context, userID int64) ([]PersonalToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at, updated_at
+		FROM personal_access_tokens
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []PersonalToken{}
+	for rows.Next() {
+		var t PersonalToken
+		if err := rows.Scan(
+			&t.ID,
+			&t.UserID,
+			&t.Name,
+			&t.TokenHash,
+			pq.Array(&t.Scopes),
+			&t.ExpiresAt,
+			&t.LastUsedAt,
+			&t.CreatedAt,
+			&t.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (s *TokenStore) GetByID(ctx context.Context, userID, tokenID int64) (*PersonalToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at, updated_at
+		FROM personal_access_tokens
+		WHERE id = $1 AND user_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return s.scanToken(s.db.QueryRowContext(ctx, query, tokenID, userID))
+}
+
+func (s *TokenStore) GetByHash(ctx context.Context, hash []byte) (*PersonalToken, error) {
+	query := `
+		SELECT id, user_id, name, token_hash, scopes, expires_at, last_used_at, created_at, updated_at
+		FROM personal_access_tokens
+		WHERE token_hash = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	return s.scanToken(s.db.QueryRowContext(ctx, query, hash))
+}
+
+func (s *TokenStore) UpdateToken(ctx context.Context, t *PersonalToken) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET name = $1, scopes = $2, updated_at = NOW()
+		WHERE id = $3 AND user_id = $4
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, t.Name, pq.Array(t.Scopes), t.ID, t.UserID)
+	if err != nil {
+		switch {
+		case err.Error() == `pq: duplicate key value violates unique constraint "unique_user_token_name"`:
+			return ErrConflict
+		default:
+			return err
+		}
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+func (s *TokenStore) DeleteToken(ctx context.Context, userID, tokenID int64) error {
+	query := `
+		DELETE FROM personal_access_tokens
+		WHERE id = $1 AND user_id = $2
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, tokenID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// TouchLastUsed is called on every authenticated request, so the row is only
+// written once a minute at most.
+func (s *TokenStore) TouchLastUsed(ctx context.Context, tokenID int64) error {
+	query := `
+		UPDATE personal_access_tokens
+		SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	_, err := s.db.ExecContext(ctx, query, tokenID)
+	return err
+}
+
+func (s *TokenStore) scanToken(row *sql.Row) (*PersonalToken, error) {
+	t := new(PersonalToken)
+	if err := row.Scan(
+		&t.ID,
+		&t.UserID,
+		&t.Name,
+		&t.TokenHash,
+		pq.Array(&t.Scopes),
+		&t.ExpiresAt,
+		&t.LastUsedAt,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+	); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return nil, ErrNotFound
+		default:
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+func deleteUserTokens(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `
+		DELETE FROM personal_access_tokens
+		WHERE user_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,162 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PurgedPost lists the remote images of a purged post, the rows are gone but
+// the files still have to be removed.
+type PurgedPost struct {
+	ID     int64
+	Images []string
+}
+
+// GetTrash returns the posts the user deleted themselves, newest first. Posts
+// removed by a moderator are not theirs to bring back.
+func (s *PostStore) GetTrash(ctx context.Context, userID int64) ([]Post, error) {
+	query := `
+		SELECT p.id, p.user_id, p.title, p.content, p.tags, p.is_edited, p.created_at, p.updated_at, p.deleted_at,
+			ARRAY(
+				SELECT ip.image_url
+				FROM images_post ip
+				WHERE ip.post_id = p.id
+				ORDER BY ip.position, ip.id
+			)
+		FROM posts p
+		WHERE p.user_id = $1 AND p.deleted_at IS NOT NULL AND p.deleted_by = p.user_id
+		ORDER BY p.deleted_at DESC, p.id DESC
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	rows, err := s.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []Post{}
+	for rows.Next() {
+		var (
+			post   Post
+			images []string
+		)
+		if err := rows.Scan(
+			&post.ID,
+			&post.UserID,
+			&post.Title,
+			&post.Content,
+			pq.Array(&post.Tags),
+			&post.IsEdited,
+			&post.CreatedAt,
+			&post.UpdatedAt,
+			&post.DeletedAt,
+			pq.Array(&images),
+		); err != nil {
+			return nil, err
+		}
+
+		for _, url := range images {
+			post.Images = append(post.Images, ImagePost{PostID: post.ID, ImageURL: url})
+		}
+		posts = append(posts, post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// RestorePost takes a post the user deleted out of the trash, as long as it
+// was deleted after the given time.
+func (s *PostStore) RestorePost(ctx context.Context, userID, postID int64, deletedAfter time.Time) error {
+	query := `
+		UPDATE posts
+		SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND user_id = $2 AND deleted_by = user_id AND deleted_at > $3
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, postID, userID, deletedAfter)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// PurgeDeleted hard deletes up to limit posts deleted before the given time,
+// the foreign keys take images, comments, reactions and revisions with them.
+// Rows locked by another replica are skipped.
+func (s *PostStore) PurgeDeleted(ctx context.Context, deletedBefore time.Time, limit int) ([]PurgedPost, error) {
+	selectQuery := `
+		SELECT p.id,
+			ARRAY(
+				SELECT ip.image_url
+				FROM images_post ip
+				WHERE ip.post_id = p.id
+			)
+		FROM posts p
+		WHERE p.deleted_at < $1
+		ORDER BY p.deleted_at
+		LIMIT $2
+		FOR UPDATE OF p SKIP LOCKED
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	var purged []PurgedPost
+	return purged, withTx(s.db, ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, selectQuery, deletedBefore, limit)
+		if err != nil {
+			return err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var post PurgedPost
+			if err := rows.Scan(
+				&post.ID,
+				pq.Array(&post.Images),
+			); err != nil {
+				return err
+			}
+			purged = append(purged, post)
+		}
+
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		if len(purged) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, 0, len(purged))
+		for _, post := range purged {
+			ids = append(ids, post.ID)
+		}
+
+		_, err = tx.ExecContext(ctx, `DELETE FROM posts WHERE id = ANY($1)`, pq.Array(ids))
+		return err
+	})
+}
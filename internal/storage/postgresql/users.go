@@ -4,21 +4,23 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 )
 
 type User struct {
-	ID        int64    `json:"id"`
-	Username  string   `json:"username"`
-	Fullname  string   `json:"fullname"`
-	Email     string   `json:"email"`
-	Password  Password `json:"-"`
-	IsActive  bool     `json:"is_active"`
-	CreatedAt string   `json:"created_at"`
-	UpdatedAt string   `json:"updated_at"`
-	Role      Role     `json:"role"`
-	ImgURL    ImgURL   `json:"image_url"`
+	ID        int64      `json:"id"`
+	Username  string     `json:"username"`
+	Fullname  string     `json:"fullname"`
+	Email     string     `json:"email"`
+	Password  Password   `json:"-"`
+	IsActive  bool       `json:"is_active"`
+	CreatedAt string     `json:"created_at"`
+	UpdatedAt string     `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at"`
+	Role      Role       `json:"role"`
+	ImgURL    ImgURL     `json:"image_url"`
 }
 
 type Password struct {
@@ -298,3 +300,165 @@ func (s *UserStorage) UpdateUser(ctx context.Context, u *User) error {
 
 	return nil
 }
+
+func (s *UserStorage) CreateAndInvite(ctx context.Context, u *User, img *ImgURL, token []byte, invitationExp time.Duration) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		user, err := s.insertUser(ctx, tx, u)
+		if err != nil {
+			return err
+		}
+
+		if err := s.insertImage(ctx, tx, user.ID, *img); err != nil {
+			return err
+		}
+
+		if err := s.createUserInvitation(ctx, tx, token, invitationExp, user.ID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (s *UserStorage) createUserInvitation(ctx context.Context, tx *sql.Tx, token []byte, exp time.Duration, userID int64) error {
+	query := `
+		INSERT INTO user_invitations (token, user_id, expiry)
+		VALUES ($1, $2, $3)
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, token, userID, time.Now().Add(exp)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserStorage) Activate(ctx context.Context, token []byte) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		userID, err := s.getUserFromInvitation(ctx, tx, token)
+		if err != nil {
+			return err
+		}
+
+		if err := s.activateUser(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		if err := s.deleteUserInvitations(ctx, tx, userID); err != nil {
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (s *UserStorage) getUserFromInvitation(ctx context.Context, tx *sql.Tx, token []byte) (int64, error) {
+	query := `
+		SELECT u.id
+		FROM users u
+		JOIN user_invitations ui ON (u.id = ui.user_id)
+		WHERE ui.token = $1 AND ui.expiry > NOW()
+		FOR UPDATE OF u
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	var userID int64
+	if err := tx.QueryRowContext(ctx, query, token).Scan(&userID); err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			return 0, ErrNotFound
+		default:
+			return 0, err
+		}
+	}
+
+	return userID, nil
+}
+
+func (s *UserStorage) activateUser(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `
+		UPDATE users
+		SET is_active = true, updated_at = NOW()
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserStorage) deleteUserInvitations(ctx context.Context, tx *sql.Tx, userID int64) error {
+	query := `
+		DELETE FROM user_invitations
+		WHERE user_id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *UserStorage) Delete(ctx context.Context, userID int64) error {
+	query := `
+		DELETE FROM users
+		WHERE id = $1
+	`
+
+	ctx, cancel := context.WithTimeout(ctx, TimeoutCtx)
+	defer cancel()
+
+	res, err := s.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
+
+// CreateWithIdentity registers a user coming from an external identity
+// provider, the provider already verified the email so the account is active
+// right away.
+func (s *UserStorage) CreateWithIdentity(ctx context.Context, u *User, img *ImgURL, identity *Identity) error {
+	return withTx(s.db, ctx, func(tx *sql.Tx) error {
+		user, err := s.insertUser(ctx, tx, u)
+		if err != nil {
+			return err
+		}
+
+		if err := s.insertImage(ctx, tx, user.ID, *img); err != nil {
+			return err
+		}
+
+		if err := s.activateUser(ctx, tx, user.ID); err != nil {
+			return err
+		}
+		user.IsActive = true
+
+		identity.UserID = user.ID
+		return insertIdentity(ctx, tx, identity)
+	})
+}
@@ -0,0 +1,80 @@
+package postgresql
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+
+	"github.com/lib/pq"
+)
+
+const (
+	VisibilityPublic    = "public"
+	VisibilityFollowers = "followers"
+	VisibilityMentioned = "mentioned"
+	VisibilityPrivate   = "private"
+)
+
+// Viewer is who a post is loaded for. ReadAny skips the visibility check,
+// for moderators who have to reach a post to act on it.
+type Viewer struct {
+	UserID  int64
+	ReadAny bool
+}
+
+var mentionPattern = regexp.MustCompile(`@([\w.-]+)`)
+
+// visibleTo is the condition for the post aliased p being readable by the
+// user in parameter $n. Every query returning posts to a user goes through
+// it, authors always see their own posts.
+func visibleTo(n int) string {
+	return fmt.Sprintf(`(
+		p.user_id = $%[1]d
+		OR p.visibility = 'public'
+		OR (p.visibility = 'followers' AND EXISTS (
+			SELECT 1 FROM follows vf WHERE vf.user_id = p.user_id AND vf.follower_id = $%[1]d
+		))
+		OR (p.visibility = 'mentioned' AND EXISTS (
+			SELECT 1 FROM post_mentions vm WHERE vm.post_id = p.id AND vm.user_id = $%[1]d
+		))
+	)`, n)
+}
+
+// mentionedUsernames returns the distinct @usernames in the content.
+func mentionedUsernames(content string) []string {
+	seen := map[string]bool{}
+	usernames := []string{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(content, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			usernames = append(usernames, match[1])
+		}
+	}
+
+	return usernames
+}
+
+// syncMentions replaces the mentions of the post with the users mentioned in
+// its content, unknown usernames are ignored.
+func syncMentions(ctx context.Context, tx *sql.Tx, postID int64, content string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM post_mentions WHERE post_id = $1`, postID); err != nil {
+		return err
+	}
+
+	usernames := mentionedUsernames(content)
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	query := `
+		INSERT INTO post_mentions (post_id, user_id)
+		SELECT $1, id
+		FROM users
+		WHERE username = ANY($2) AND id <> (SELECT user_id FROM posts WHERE id = $1)
+		ON CONFLICT DO NOTHING
+	`
+
+	_, err := tx.ExecContext(ctx, query, postID, pq.Array(usernames))
+	return err
+}
@@ -41,3 +41,8 @@ func (e *ErrorUtils) ForbiddenError(w http.ResponseWriter, r *http.Request) {
 	log.Printf("forbidden error, method: %v, path :%v, message: %v", r.Method, r.URL.Path, err)
 	e.json.WriteJSONError(w, http.StatusForbidden, err)
 }
+
+func (e *ErrorUtils) TooManyRequestsError(w http.ResponseWriter, r *http.Request, err error) {
+	log.Printf("too many requests error, method: %v, path :%v, message: %v", r.Method, r.URL.Path, err.Error())
+	e.json.WriteJSONError(w, http.StatusTooManyRequests, err.Error())
+}
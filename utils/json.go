@@ -18,6 +18,10 @@ func (j *JsonUtils) writeJSON(w http.ResponseWriter, status int, data any) error
 	return json.NewEncoder(w).Encode(data)
 }
 
+func (j *JsonUtils) WriteJSON(w http.ResponseWriter, status int, data any) error {
+	return j.writeJSON(w, status, data)
+}
+
 func (j *JsonUtils) ReadFormData(w http.ResponseWriter, r *http.Request, data any) error {
 	// Tentukan batas ukuran form
 	err := r.ParseMultipartForm(10 << 20) // 10 MB